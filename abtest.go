@@ -0,0 +1,184 @@
+package statext
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ABTest is a Bayesian A/B/n test of the conversion rates of several
+// variants, with variant 0 the control. The rate of each variant has a
+// Beta(PriorAlpha+Successes[i], PriorBeta+Trials[i]-Successes[i]) posterior.
+type ABTest struct {
+	// Successes and Trials hold the number of conversions and the number of
+	// trials of each variant.
+	Successes, Trials []float64
+	// PriorAlpha and PriorBeta are the parameters of the Beta prior of the
+	// rate of each variant, such as 1 and 1 for a uniform prior.
+	PriorAlpha, PriorBeta float64
+	// Level is the probability mass of the credible intervals, such as 0.95.
+	Level float64
+}
+
+// ABTestResult holds the result of ABTest.Analyze.
+type ABTestResult struct {
+	// Probs holds the probabilities that each variant has the highest rate.
+	Probs []float64
+	// Loss holds the expected loss of choosing each variant, the expected
+	// shortfall of its rate from the highest.
+	Loss []float64
+	// BeatControl holds the probabilities that each variant has a higher
+	// rate than the control. It is 0 for the control.
+	BeatControl []float64
+	// LiftLower and LiftUpper hold the equal-tailed credible interval of the
+	// relative lift of each variant over the control, p[i]/p[0] - 1.
+	LiftLower, LiftUpper []float64
+	// OddsRatioLower and OddsRatioUpper hold the equal-tailed credible
+	// interval of the odds ratio of each variant to the control,
+	// (p[i]/(1-p[i])) / (p[0]/(1-p[0])).
+	OddsRatioLower, OddsRatioUpper []float64
+}
+
+// Analyze computes the posterior summaries of the test.
+// Probs and Loss are computed with BetaWinner. The distributions of the lift
+// and odds ratio are integrated over the posterior of the control: the odds
+// of a Beta(α, β) distributed rate are BetaPrime(α, β) distributed, so the
+// odds ratio has cdf
+//  P(OR <= r) = E[BetaPrime(α_i, β_i).CDF(r*p[0]/(1-p[0]))]
+// and likewise the lift with the Beta cdf. The intervals are found by a
+// bracketed root-finder on these cdfs.
+// As BetaWinner, Analyze returns a result with nil slices if tol is NaN or
+// less than MinDirichletWinnerTol. It panics if the slice lengths differ, if
+// any posterior parameter is not positive, or if Level is not in (0, 1).
+func (t ABTest) Analyze(tol float64) ABTestResult {
+	n := len(t.Successes)
+	if len(t.Trials) != n {
+		panic("ab test: slice length mismatch")
+	}
+	if !(tol >= MinDirichletWinnerTol) {
+		return ABTestResult{}
+	}
+	if !(t.Level > 0 && t.Level < 1) {
+		panic("ab test: bad credible level")
+	}
+	alphas := make([]float64, n)
+	betas := make([]float64, n)
+	for i, s := range t.Successes {
+		alphas[i] = t.PriorAlpha + s
+		betas[i] = t.PriorBeta + t.Trials[i] - s
+		if !(alphas[i] > 0 && betas[i] > 0) {
+			panic("ab test: posterior parameter not positive")
+		}
+	}
+	res := ABTestResult{
+		BeatControl:    make([]float64, n),
+		LiftLower:      make([]float64, n),
+		LiftUpper:      make([]float64, n),
+		OddsRatioLower: make([]float64, n),
+		OddsRatioUpper: make([]float64, n),
+	}
+	res.Probs, res.Loss = BetaWinner(alphas, betas, tol)
+	if n == 0 {
+		return res
+	}
+	lo, hi := (1-t.Level)/2, (1+t.Level)/2
+	res.OddsRatioLower[0], res.OddsRatioUpper[0] = 1, 1
+	for i := 1; i < n; i++ {
+		r := betaRatio{a0: alphas[0], b0: betas[0], a1: alphas[i], b1: betas[i], tol: tol}
+		res.BeatControl[i] = 1 - r.cdf(1)
+		res.LiftLower[i] = r.quantile(lo) - 1
+		res.LiftUpper[i] = r.quantile(hi) - 1
+		r.odds = true
+		res.OddsRatioLower[i] = r.quantile(lo)
+		res.OddsRatioUpper[i] = r.quantile(hi)
+	}
+	return res
+}
+
+// betaRatio is the distribution of g(X1)/g(X0), for independent X0 distributed
+// Beta(a0, b0) and X1 distributed Beta(a1, b1), where g is the identity, or if
+// odds is set, the odds x/(1-x).
+type betaRatio struct {
+	a0, b0, a1, b1 float64
+	odds           bool
+	tol            float64
+}
+
+// cdf computes P(g(X1)/g(X0) <= r), integrating the cdf of g(X1), a Beta
+// or for the odds a BetaPrime distribution, over X0 with globally adaptive
+// Gauss-Kronrod quadrature and the change of variables x = sin(πy/2)^2,
+// which cancels the endpoint singularities of the Beta pdf for shapes of
+// at least 0.5.
+func (d betaRatio) cdf(r float64) float64 {
+	lbeta := mathext.Lbeta(d.a0, d.b0)
+	odds := BetaPrime{Alpha: d.a1, Beta: d.b1}
+	q := gaussKronrodQuad(func(y float64, dst []float64) {
+		s, c := math.Sincos(math.Pi * y / 2)
+		x := s * s
+		pdf := math.Exp(math.Log(s)*(2*d.a0-1) + math.Log(c)*(2*d.b0-1) - lbeta + math.Log(math.Pi))
+		if d.odds {
+			dst[0] = pdf * odds.CDF(r*x/(1-x))
+		} else {
+			dst[0] = pdf * mathext.RegIncBeta(d.a1, d.b1, math.Min(r*x, 1))
+		}
+		if math.IsInf(dst[0], 0) || math.IsNaN(dst[0]) {
+			// Integrable singularity at an endpoint
+			dst[0] = 0.0
+		}
+	}, 1, d.tol)
+	return math.Max(math.Min(q.result[0], 1), 0)
+}
+
+// quantile finds the p quantile of the ratio with the Illinois variant of
+// regula falsi on the log of the ratio, bracketed by the quantiles of g(X0)
+// and g(X1): the ratio is below g1(p/2)/g0(1-p/2) with probability at most p,
+// and below g1((1+p)/2)/g0((1-p)/2) with probability at least p.
+// It stops once the cdf is within the tolerance of p.
+func (d betaRatio) quantile(p float64) float64 {
+	var q0, q1 func(p float64) float64
+	if d.odds {
+		q0 = BetaPrime{Alpha: d.a0, Beta: d.b0}.Quantile
+		q1 = BetaPrime{Alpha: d.a1, Beta: d.b1}.Quantile
+	} else {
+		q0 = distuv.Beta{Alpha: d.a0, Beta: d.b0}.Quantile
+		q1 = distuv.Beta{Alpha: d.a1, Beta: d.b1}.Quantile
+	}
+	const maxLog = 700 // Keep the bracket finite where the quantiles underflow
+	lo := math.Max(math.Log(q1(p/2))-math.Log(q0(1-p/2)), -maxLog)
+	hi := math.Min(math.Log(q1((1+p)/2))-math.Log(q0((1-p)/2)), maxLog)
+	flo, fhi := d.cdf(math.Exp(lo))-p, d.cdf(math.Exp(hi))-p
+	if flo >= 0 {
+		return math.Exp(lo)
+	}
+	if fhi <= 0 {
+		return math.Exp(hi)
+	}
+	side := 0
+	for i := 0; i < 100 && hi-lo > 1e-12*math.Max(1, math.Abs(lo)); i++ {
+		mid := (lo*fhi - hi*flo) / (fhi - flo)
+		if !(mid > lo && mid < hi) {
+			mid = lo + (hi-lo)/2
+		}
+		f := d.cdf(math.Exp(mid)) - p
+		if math.Abs(f) <= d.tol {
+			return math.Exp(mid)
+		}
+		// Halve the value at an endpoint retained twice in a row, so that
+		// it is not retained indefinitely.
+		if f < 0 {
+			lo, flo = mid, f
+			if side == -1 {
+				fhi /= 2
+			}
+			side = -1
+		} else {
+			hi, fhi = mid, f
+			if side == 1 {
+				flo /= 2
+			}
+			side = 1
+		}
+	}
+	return math.Exp(lo + (hi-lo)/2)
+}
@@ -0,0 +1,104 @@
+package statext
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestABTest(t *testing.T) {
+	test := ABTest{
+		Successes:  []float64{41, 52, 30},
+		Trials:     []float64{1000, 1010, 950},
+		PriorAlpha: 1,
+		PriorBeta:  1,
+		Level:      0.9,
+	}
+	const tol = 1e-10
+	res := test.Analyze(tol)
+	probs, loss := BetaWinner([]float64{42, 53, 31}, []float64{960, 959, 921}, tol)
+	for i := range probs {
+		if res.Probs[i] != probs[i] || res.Loss[i] != loss[i] {
+			t.Errorf("ABTest winner mismatch. got %v %v want %v %v", res.Probs, res.Loss, probs, loss)
+		}
+	}
+	if res.BeatControl[0] != 0 || res.LiftLower[0] != 0 || res.LiftUpper[0] != 0 || res.OddsRatioLower[0] != 1 || res.OddsRatioUpper[0] != 1 {
+		t.Errorf("ABTest control mismatch: %+v", res)
+	}
+	// With two variants, beating the control is winning.
+	two := ABTest{Successes: test.Successes[:2], Trials: test.Trials[:2], PriorAlpha: 1, PriorBeta: 1, Level: 0.9}
+	p, _ := BetaWinner([]float64{42, 53}, []float64{960, 959}, tol)
+	if got := two.Analyze(tol).BeatControl[1]; math.Abs(got-p[1]) > 1e-8 {
+		t.Errorf("ABTest beat control mismatch. got %v want %v", got, p[1])
+	}
+	for _, tol := range []float64{0, -1, math.NaN(), 1e-15} {
+		if r := test.Analyze(tol); r.Probs != nil || r.Loss != nil || r.BeatControl != nil || r.LiftLower != nil || r.OddsRatioUpper != nil {
+			t.Errorf("ABTest accepted tol %v: %+v", tol, r)
+		}
+	}
+
+	// Compare the intervals with the quantiles of Monte Carlo samples.
+	src := rand.NewSource(1)
+	const samples = 100000
+	control := distuv.Beta{Alpha: 42, Beta: 960, Src: src}
+	for i := 1; i < 3; i++ {
+		variant := distuv.Beta{Alpha: 1 + test.Successes[i], Beta: 1 + test.Trials[i] - test.Successes[i], Src: src}
+		lifts := make([]float64, samples)
+		odds := make([]float64, samples)
+		var beat float64
+		for k := range lifts {
+			p0, p1 := control.Rand(), variant.Rand()
+			lifts[k] = p1/p0 - 1
+			odds[k] = p1 / (1 - p1) / (p0 / (1 - p0))
+			if p1 > p0 {
+				beat++
+			}
+		}
+		if math.Abs(beat/samples-res.BeatControl[i]) > 0.005 {
+			t.Errorf("ABTest beat control mismatch. got %v simulated %v", res.BeatControl[i], beat/samples)
+		}
+		sort.Float64s(lifts)
+		sort.Float64s(odds)
+		for _, c := range []struct {
+			sorted       []float64
+			lower, upper float64
+		}{
+			{lifts, res.LiftLower[i], res.LiftUpper[i]},
+			{odds, res.OddsRatioLower[i], res.OddsRatioUpper[i]},
+		} {
+			// The fraction of samples outside each bound should be 0.05.
+			below := float64(sort.SearchFloat64s(c.sorted, c.lower)) / samples
+			above := 1 - float64(sort.SearchFloat64s(c.sorted, c.upper))/samples
+			if math.Abs(below-0.05) > 0.004 || math.Abs(above-0.05) > 0.004 {
+				t.Errorf("ABTest interval mismatch. interval: [%v, %v] tails: %v %v", c.lower, c.upper, below, above)
+			}
+		}
+	}
+}
+
+func TestBetaRatioOddsCDF(t *testing.T) {
+	// With equal shapes, the ratio of the two odds is symmetric about 1 on
+	// the log scale.
+	d := betaRatio{a0: 3, b0: 5, a1: 3, b1: 5, odds: true, tol: 1e-10}
+	if got := d.cdf(1); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("betaRatio odds cdf mismatch at 1: got %v want 0.5", got)
+	}
+	if got, want := d.cdf(2), 1-d.cdf(0.5); math.Abs(got-want) > 1e-9 {
+		t.Errorf("betaRatio odds cdf asymmetry: got %v want %v", got, want)
+	}
+}
+
+func TestBetaRatioQuantile(t *testing.T) {
+	const tol = 1e-10
+	for _, odds := range []bool{false, true} {
+		d := betaRatio{a0: 42, b0: 960, a1: 53, b1: 959, odds: odds, tol: tol}
+		for _, p := range []float64{0.025, 0.05, 0.5, 0.95, 0.975} {
+			if got := d.cdf(d.quantile(p)); math.Abs(got-p) > 10*tol {
+				t.Errorf("betaRatio cdf of quantile mismatch. odds: %v p: %v got: %v", odds, p, got)
+			}
+		}
+	}
+}
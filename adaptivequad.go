@@ -0,0 +1,214 @@
+package statext
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	maxDepth = 50 // Integrate to at most this depth (should never be reached)
+	minDepth = 2  // Integrate to at least this depth
+)
+
+// adaptiveQuad implements an adaptive Simpson quadrature integration of a
+// vector-valued function over [0, 1].
+type adaptiveQuad struct {
+	// f computes the integrand at y in-place on dst.
+	f   func(y float64, dst []float64)
+	tol float64
+
+	result []float64 // Integral estimates
+	errs   []float64 // Estimated absolute error of each integral
+	evals  int       // Number of evaluations of f
+	depth  int       // Deepest subdivision reached
+	capped bool      // Whether any subinterval reached maxDepth
+
+	start, end float64   // Integration domain, a subinterval of [0, 1]
+	startDepth int       // Depth of the domain within [0, 1]
+	fs         []float64 // f at the start point
+	fe         []float64 // f at the end point
+	ft         []float64 // Buffer space to use as fs/fe at lower depths
+
+	// parts split the domain for integration in parallel, if non-empty.
+	parts []*adaptiveQuad
+}
+
+// newAdaptiveQuad returns an adaptiveQuad for the n-dimensional integrand f.
+func newAdaptiveQuad(f func(y float64, dst []float64), n int, tol float64) *adaptiveQuad {
+	q := &adaptiveQuad{f: f, end: 1.0}
+	q.reset(n, tol)
+	return q
+}
+
+// reset prepares q for an n-dimensional integrand with the given tolerance,
+// reusing the existing buffers where possible.
+func (q *adaptiveQuad) reset(n int, tol float64) {
+	q.tol = tol
+	q.evals = 0
+	q.result = resize(q.result, n)
+	q.errs = resize(q.errs, n)
+	q.fs = resize(q.fs, n)
+	q.fe = resize(q.fe, n)
+	q.ft = resize(q.ft, maxDepth*n)
+	for _, p := range q.parts {
+		p.reset(n, tol)
+	}
+}
+
+// split divides the domain of q evenly into chunks parts, rounded up to a
+// power of two, to be integrated in parallel. The parts are split at the
+// midpoints the serial integration uses, so for up to 1<<minDepth parts the
+// results match it (up to rounding in the summation).
+// Values of chunks less than 2 remove any split.
+func (q *adaptiveQuad) split(chunks int) {
+	q.parts = q.parts[:0]
+	if chunks < 2 {
+		return
+	}
+	depth := 0
+	for 1<<uint(depth) < chunks {
+		depth++
+	}
+	chunks = 1 << uint(depth)
+	width := (q.end - q.start) / float64(chunks)
+	for k := 0; k < chunks; k++ {
+		p := &adaptiveQuad{
+			f:          q.f,
+			start:      q.start + float64(k)*width,
+			end:        q.start + float64(k+1)*width,
+			startDepth: q.startDepth + depth,
+		}
+		if k == chunks-1 {
+			p.end = q.end
+		}
+		p.reset(len(q.result), q.tol)
+		q.parts = append(q.parts, p)
+	}
+}
+
+// eval computes f at y in-place on dst.
+func (q *adaptiveQuad) eval(y float64, dst []float64) {
+	q.evals++
+	q.f(y, dst)
+}
+
+// evalEndpoints computes f at the endpoints of the domain of q, or of each of
+// its parts.
+func (q *adaptiveQuad) evalEndpoints() {
+	if len(q.parts) == 0 {
+		q.eval(q.start, q.fs) // Compute start point function result
+		q.eval(q.end, q.fe)   // Compute end point function result
+		return
+	}
+	for _, p := range q.parts {
+		p.evalEndpoints()
+	}
+}
+
+// integrate computes the integrals, repeating with increasing minimum depths
+// until the sum of the first m results is within sumTol of want.
+// It reports whether the sum condition was met. If a pass reaches maxDepth
+// without improving on the previous pass, the integral isn't converging
+// (e.g. due to a singularity), so integrate gives up.
+func (q *adaptiveQuad) integrate(m int, want, sumTol float64) bool {
+	q.evalEndpoints()
+	prevErr := math.Inf(1)
+	for md := minDepth; md < maxDepth; md++ {
+		q.pass(md)
+		var sr float64
+		for _, v := range q.result[:m] {
+			sr += v
+		}
+		sumErr := math.Abs(sr - want)
+		if sumErr <= sumTol {
+			return true
+		}
+		if q.capped && sumErr >= prevErr {
+			return false
+		}
+		prevErr = sumErr
+	}
+	return false
+}
+
+// pass runs a single integration with minimum depth md, in parallel over the
+// parts of q if it has been split.
+func (q *adaptiveQuad) pass(md int) {
+	for i := range q.result {
+		q.result[i] = 0
+		q.errs[i] = 0
+	}
+	q.depth = 0
+	q.capped = false
+	if len(q.parts) == 0 {
+		q.recurse(q.start, q.end, q.fs, q.fe, q.ft, q.startDepth, md)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(q.parts))
+	for _, p := range q.parts {
+		go func(p *adaptiveQuad) {
+			p.pass(md)
+			wg.Done()
+		}(p)
+	}
+	wg.Wait()
+	q.evals = 0
+	for _, p := range q.parts {
+		for i := range q.result {
+			q.result[i] += p.result[i]
+			q.errs[i] += p.errs[i]
+		}
+		q.evals += p.evals
+		if p.depth > q.depth {
+			q.depth = p.depth
+		}
+		q.capped = q.capped || p.capped
+	}
+}
+
+// recurse integrates f over [s, e], given the function results fs and fe at
+// the endpoints, adding the results to q.result.
+func (q *adaptiveQuad) recurse(s, e float64, fs, fe, ft []float64, depth, mnDepth int) {
+	n := len(q.result)
+	if depth > q.depth {
+		q.depth = depth
+	}
+	if depth == maxDepth {
+		q.capped = true
+		for i := 0; i < n; i++ {
+			// Average the endpoints and return
+			q.result[i] += (fs[i] + fe[i]) * (e - s) / 2
+			q.errs[i] += math.Abs(fs[i]-fe[i]) * (e - s) / 2
+		}
+		//panic("Max depth")
+		return
+	}
+	var Q, Q2 float64
+	q.eval((s+e)/2, ft[:n])
+	for i := 0; i < n; i++ {
+		Q = (fs[i] + fe[i]) * (e - s) / 2
+		Q2 = (fs[i] + 4*ft[i] + fe[i]) * (e - s) / 6
+		if math.Abs(Q-Q2) >= q.tol || depth < mnDepth {
+			// Error too large, divide
+			q.recurse(s, (s+e)/2, fs, ft[:n], ft[n:], depth+1, mnDepth) // Left-half integration
+			q.recurse((s+e)/2, e, ft[:n], fe, ft[n:], depth+1, mnDepth) // Right-half integration
+			return
+		}
+	}
+	// Small enough error, return
+	for i := 0; i < n; i++ {
+		Q = (fs[i] + fe[i]) * (e - s) / 2
+		Q2 = (fs[i] + 4*ft[i] + fe[i]) * (e - s) / 6
+		q.result[i] += Q2
+		q.errs[i] += math.Abs(Q - Q2)
+	}
+}
+
+// resize returns s resized to length n, reusing its capacity if possible.
+func resize(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	return s[:n]
+}
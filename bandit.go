@@ -0,0 +1,288 @@
+package statext
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// BanditModel is the posterior model of a Bandit.
+type BanditModel int
+
+const (
+	// DirichletBandit models the arms as the categories of a single
+	// categorical outcome, such as which of several variants a user picks,
+	// with a Dirichlet posterior over their shares. The winner probabilities
+	// are computed with DirichletWinner.
+	DirichletBandit BanditModel = iota
+	// BetaBandit models each arm as an independent Bernoulli outcome, with
+	// a Beta posterior over its success rate. The winner probabilities are
+	// computed with BetaWinner.
+	BetaBandit
+)
+
+// BanditPolicy is the allocation policy of a Bandit.
+type BanditPolicy int
+
+const (
+	// ProbabilityMatching allocates to each arm its probability of being
+	// the best, as in Thompson sampling.
+	ProbabilityMatching BanditPolicy = iota
+	// TopTwoThompson allocates as top-two Thompson sampling: the leader of a
+	// Thompson sample is played with probability TopTwo, and otherwise the
+	// leader of fresh samples is played once it differs. Arm i is then
+	// played with probability
+	//  TopTwo*p[i] + (1-TopTwo)*p[i]*sum(p[j]/(1-p[j]), j != i)
+	// where p are the winner probabilities. See:
+	//  Russo, Daniel. "Simple Bayesian algorithms for best arm identification."
+	//  Conference on Learning Theory (2016).
+	TopTwoThompson
+)
+
+// Bandit maintains the posteriors of the arms of a multi-armed bandit, and
+// allocates traffic across them with the winner probabilities.
+// A Bandit must not be used concurrently.
+type Bandit struct {
+	// Model is the posterior model of the arms.
+	Model BanditModel
+	// Policy is the allocation policy of Weights.
+	Policy BanditPolicy
+	// Alphas holds the Dirichlet parameters of a DirichletBandit, or the
+	// first Beta parameters (prior plus successes) of a BetaBandit.
+	Alphas []float64
+	// Betas holds the second Beta parameters (prior plus failures) of a
+	// BetaBandit, and is unused by a DirichletBandit.
+	Betas []float64
+	// TopTwo is the probability with which TopTwoThompson plays the leader,
+	// commonly 0.5.
+	TopTwo float64
+	// MinWeight is the least weight Weights allocates to each arm, to
+	// guarantee some exploration. The remaining 1-len(Alphas)*MinWeight is
+	// allocated by the policy.
+	MinWeight float64
+	// Tol is the tolerance of the winner probabilities, with 0 taken as
+	// DefaultBanditTol. Other values must be at least MinDirichletWinnerTol.
+	Tol float64
+}
+
+// DefaultBanditTol is the tolerance of the winner probabilities of a Bandit
+// with Tol unset.
+const DefaultBanditTol = 1e-8
+
+// NewDirichletBandit returns a DirichletBandit with the given prior alphas,
+// allocating by ProbabilityMatching. The prior is copied.
+func NewDirichletBandit(prior []float64, tol float64) *Bandit {
+	return &Bandit{
+		Model:  DirichletBandit,
+		Alphas: append([]float64(nil), prior...),
+		TopTwo: 0.5,
+		Tol:    tol,
+	}
+}
+
+// NewBetaBandit returns a BetaBandit with the given prior alphas and betas,
+// allocating by ProbabilityMatching. The priors are copied.
+// NewBetaBandit panics if len(alphas) != len(betas).
+func NewBetaBandit(alphas, betas []float64, tol float64) *Bandit {
+	if len(alphas) != len(betas) {
+		panic("bandit: slice length mismatch")
+	}
+	return &Bandit{
+		Model:  BetaBandit,
+		Alphas: append([]float64(nil), alphas...),
+		Betas:  append([]float64(nil), betas...),
+		TopTwo: 0.5,
+		Tol:    tol,
+	}
+}
+
+// Observe updates the posterior of arm with an observed reward.
+// For a DirichletBandit, reward is the number of times the category arm was
+// observed. For a BetaBandit, reward is the success of the Bernoulli outcome,
+// in [0, 1], with fractional rewards counting as partial successes.
+// Observe panics if reward is negative, or for a BetaBandit, greater than 1.
+func (b *Bandit) Observe(arm int, reward float64) {
+	if !(reward >= 0) || (b.Model == BetaBandit && reward > 1) {
+		panic("bandit: reward out of range")
+	}
+	b.Alphas[arm] += reward
+	if b.Model == BetaBandit {
+		b.Betas[arm] += 1 - reward
+	}
+}
+
+// WinProbabilities returns the posterior probabilities that each arm is
+// the best.
+// WinProbabilities panics if Tol is invalid, or if any posterior parameter
+// is not positive and finite.
+func (b *Bandit) WinProbabilities() []float64 {
+	tol := b.Tol
+	if tol == 0 {
+		tol = DefaultBanditTol
+	}
+	if !(tol >= MinDirichletWinnerTol) {
+		panic("bandit: bad tolerance")
+	}
+	var probs []float64
+	switch b.Model {
+	case DirichletBandit:
+		var err error
+		probs, err = DirichletWinnerE(b.Alphas, tol)
+		if err == ErrNoAlphas {
+			return []float64{}
+		}
+	case BetaBandit:
+		for i, alpha := range b.Alphas {
+			if !(alpha > 0 && b.Betas[i] > 0) || math.IsInf(alpha, 1) || math.IsInf(b.Betas[i], 1) {
+				panic("bandit: posterior parameter not positive and finite")
+			}
+		}
+		probs, _ = BetaWinner(b.Alphas, b.Betas, tol)
+	default:
+		panic("bandit: unknown model")
+	}
+	if probs == nil {
+		panic("bandit: posterior parameter not positive and finite")
+	}
+	return probs
+}
+
+// Weights returns the allocation weights of the arms under the policy,
+// which sum to 1.
+// Weights panics if len(Alphas)*MinWeight is greater than 1, or as
+// WinProbabilities.
+func (b *Bandit) Weights() []float64 {
+	n := len(b.Alphas)
+	if float64(n)*b.MinWeight > 1 {
+		panic("bandit: minimum weight too large")
+	}
+	probs := b.WinProbabilities()
+	var weights []float64
+	switch b.Policy {
+	case ProbabilityMatching:
+		weights = probs
+	case TopTwoThompson:
+		weights = topTwoWeights(probs, b.TopTwo)
+	default:
+		panic("bandit: unknown policy")
+	}
+	var sum float64
+	for i, w := range weights {
+		if !(w > 0) {
+			weights[i] = 0
+		}
+		sum += weights[i]
+	}
+	if sum == 0 {
+		// Nothing to allocate by, so allocate uniformly.
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = float64(n)
+	}
+	for i, w := range weights {
+		weights[i] = b.MinWeight + (1-float64(n)*b.MinWeight)*w/sum
+	}
+	return weights
+}
+
+// topTwoWeights returns the top-two Thompson sampling weights for the
+// winner probabilities probs, playing the leader with probability beta.
+// Leaders with probability 1 of winning are never resampled past, so they
+// contribute only to their own weight.
+func topTwoWeights(probs []float64, beta float64) []float64 {
+	// The challenger, given leader j, is i with probability p[i]/(1-p[j]).
+	var sum float64
+	for _, p := range probs {
+		if p < 1 {
+			sum += p / (1 - p)
+		}
+	}
+	weights := make([]float64, len(probs))
+	for i, p := range probs {
+		if p >= 1 {
+			weights[i] = p
+			continue
+		}
+		weights[i] = beta*p + (1-beta)*p*(sum-p/(1-p))
+	}
+	return weights
+}
+
+// BanditEvent is a logged bandit event: the arm played and the reward
+// observed.
+type BanditEvent struct {
+	Arm    int
+	Reward float64
+}
+
+// BanditReplayResult holds the result of Bandit.Replay.
+type BanditReplayResult struct {
+	// Accepted is the number of events whose arm matched the bandit's choice.
+	Accepted int
+	// Reward is the total reward of the accepted events.
+	Reward float64
+	// Pulls holds the number of accepted events of each arm.
+	Pulls []int
+}
+
+// Replay evaluates the bandit offline on logged events, with the replay
+// method: for each event in turn, an arm is drawn from the allocation
+// weights with the random source src, and if it matches the logged arm,
+// the event is accepted and its reward observed. Other events are skipped.
+// The weights are recomputed after every batch accepted events, as they are
+// expensive to compute; a batch less than 1 is taken as 1.
+// If the events were logged under uniformly random allocation, the average
+// reward of the accepted events is an unbiased estimate of the bandit's
+// online average reward. See:
+//  Li, Lihong, et al. "Unbiased offline evaluation of contextual-bandit-based
+//  news article recommendation algorithms." Proceedings of the fourth ACM
+//  international conference on Web search and data mining (2011): 297-306.
+// Replay updates the posteriors of b, and panics if any event's arm is out
+// of range, or as Observe and Weights.
+func (b *Bandit) Replay(events []BanditEvent, batch int, src rand.Source) BanditReplayResult {
+	n := len(b.Alphas)
+	res := BanditReplayResult{Pulls: make([]int, n)}
+	if batch < 1 {
+		batch = 1
+	}
+	rnd := newRander(src)
+	var weights []float64
+	for _, e := range events {
+		if e.Arm < 0 || e.Arm >= n {
+			panic("bandit: arm out of range")
+		}
+		if weights == nil {
+			weights = b.Weights()
+		}
+		if sampleWeights(weights, rnd.float64()) != e.Arm {
+			continue
+		}
+		b.Observe(e.Arm, e.Reward)
+		res.Accepted++
+		res.Reward += e.Reward
+		res.Pulls[e.Arm]++
+		if res.Accepted%batch == 0 {
+			weights = nil
+		}
+	}
+	return res
+}
+
+// sampleWeights returns the index drawn from the weights, which sum to 1,
+// for the uniform variate u.
+func sampleWeights(weights []float64, u float64) int {
+	for i, w := range weights {
+		u -= w
+		if u < 0 {
+			return i
+		}
+	}
+	// Rounding left u past the total, so take the last nonzero weight.
+	for i := len(weights) - 1; i > 0; i-- {
+		if weights[i] > 0 {
+			return i
+		}
+	}
+	return 0
+}
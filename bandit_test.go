@@ -0,0 +1,168 @@
+package statext
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestBanditWeights(t *testing.T) {
+	for _, b := range []*Bandit{
+		NewDirichletBandit([]float64{5.5, 10.5, 15.5}, 1e-10),
+		NewBetaBandit([]float64{3, 5, 4, 1}, []float64{7, 6, 9, 1}, 1e-10),
+	} {
+		probs := b.WinProbabilities()
+		w := b.Weights()
+		if !floats.EqualApprox(w, probs, 1e-9) {
+			t.Errorf("Probability matching weights mismatch. got %v want %v", w, probs)
+		}
+		b.MinWeight = 0.1
+		w = b.Weights()
+		for i, p := range probs {
+			want := 0.1 + (1-0.1*float64(len(probs)))*p
+			if math.Abs(w[i]-want) > 1e-9 {
+				t.Errorf("Minimum weight mismatch. got %v want %v", w[i], want)
+			}
+		}
+		b.MinWeight = 0
+		b.Policy = TopTwoThompson
+		w = b.Weights()
+		if math.Abs(floats.Sum(w)-1) > 1e-12 {
+			t.Errorf("Top-two weights sum error: %v", floats.Sum(w))
+		}
+		// Simulate top-two Thompson sampling directly.
+		rnd := rand.New(rand.NewSource(1))
+		n := len(b.Alphas)
+		counts := make([]float64, n)
+		x := make([]float64, n)
+		sample := func() int {
+			for i, alpha := range b.Alphas {
+				if b.Model == BetaBandit {
+					x[i] = distuv.Beta{Alpha: alpha, Beta: b.Betas[i], Src: rnd}.Rand()
+				} else {
+					x[i] = distuv.Gamma{Alpha: alpha, Beta: 1, Src: rnd}.Rand()
+				}
+			}
+			return floats.MaxIdx(x)
+		}
+		const samples = 100000
+		for k := 0; k < samples; k++ {
+			leader := sample()
+			if rnd.Float64() < b.TopTwo {
+				counts[leader]++
+				continue
+			}
+			challenger := sample()
+			for challenger == leader {
+				challenger = sample()
+			}
+			counts[challenger]++
+		}
+		for i, c := range counts {
+			p := c / samples
+			if math.Abs(p-w[i]) > 4*math.Sqrt(w[i]*(1-w[i])/samples)+1e-9 {
+				t.Errorf("Top-two weights mismatch. got %v simulated %v", w, counts)
+			}
+		}
+	}
+}
+
+func TestTopTwoWeightsCertainLeader(t *testing.T) {
+	w := topTwoWeights([]float64{0, 1, 0}, 0.5)
+	if !floats.Equal(w, []float64{0, 1, 0}) {
+		t.Errorf("Top-two weights with a certain leader: got %v", w)
+	}
+}
+
+func TestBanditObserve(t *testing.T) {
+	d := NewDirichletBandit([]float64{1, 1}, 1e-8)
+	d.Observe(1, 3)
+	if !floats.Equal(d.Alphas, []float64{1, 4}) {
+		t.Errorf("Dirichlet bandit observe: got %v", d.Alphas)
+	}
+	b := NewBetaBandit([]float64{1, 1}, []float64{1, 1}, 1e-8)
+	b.Observe(0, 1)
+	b.Observe(0, 0)
+	b.Observe(1, 0.25)
+	if !floats.Equal(b.Alphas, []float64{2, 1.25}) || !floats.Equal(b.Betas, []float64{2, 1.75}) {
+		t.Errorf("Beta bandit observe: got %v %v", b.Alphas, b.Betas)
+	}
+}
+
+func TestBanditValidation(t *testing.T) {
+	// Tol defaults when unset.
+	for _, b := range []*Bandit{
+		{Model: DirichletBandit, Alphas: []float64{2, 3}},
+		{Model: BetaBandit, Alphas: []float64{2, 3}, Betas: []float64{3, 2}},
+	} {
+		w := b.Weights()
+		if len(w) != 2 || math.Abs(floats.Sum(w)-1) > 1e-12 || !(w[1] > w[0]) {
+			t.Errorf("Bandit weights with default tolerance: got %v", w)
+		}
+	}
+	if w := (&Bandit{}).Weights(); len(w) != 0 {
+		t.Errorf("Bandit weights with no arms: got %v", w)
+	}
+
+	panics := func(f func()) (panicked bool) {
+		defer func() {
+			panicked = recover() != nil
+		}()
+		f()
+		return false
+	}
+	for i, f := range []func(){
+		func() { NewBetaBandit([]float64{1}, []float64{1}, 1e-8).Observe(0, 1.5) },
+		func() { NewBetaBandit([]float64{1}, []float64{1}, 1e-8).Observe(0, -0.5) },
+		func() { NewDirichletBandit([]float64{1}, 1e-8).Observe(0, -1) },
+		func() { NewDirichletBandit([]float64{1}, 1e-8).Observe(0, math.NaN()) },
+		func() { NewDirichletBandit([]float64{1, 2}, -1).Weights() },
+		func() { NewBetaBandit([]float64{1, 2}, []float64{1, 2}, math.NaN()).Weights() },
+		func() { NewDirichletBandit([]float64{0, 2}, 1e-8).Weights() },
+		func() { NewBetaBandit([]float64{1, 2}, []float64{0, 2}, 1e-8).Weights() },
+	} {
+		if !panics(f) {
+			t.Errorf("Bandit validation case %v did not panic", i)
+		}
+	}
+}
+
+func TestBanditReplay(t *testing.T) {
+	// Log events under uniformly random allocation, with the last arm best.
+	rates := []float64{0.1, 0.12, 0.2}
+	rnd := rand.New(rand.NewSource(1))
+	events := make([]BanditEvent, 30000)
+	var logged float64
+	for k := range events {
+		arm := rnd.Intn(len(rates))
+		events[k] = BanditEvent{Arm: arm}
+		if rnd.Float64() < rates[arm] {
+			events[k].Reward = 1
+		}
+		logged += events[k].Reward
+	}
+	replay := func() BanditReplayResult {
+		b := NewBetaBandit([]float64{1, 1, 1}, []float64{1, 1, 1}, 1e-8)
+		return b.Replay(events, 20, rand.NewSource(2))
+	}
+	res := replay()
+	if res2 := replay(); res2.Accepted != res.Accepted || res2.Reward != res.Reward {
+		t.Errorf("Bandit replay not reproducible: %v %v", res, res2)
+	}
+	total := 0
+	for _, p := range res.Pulls {
+		total += p
+	}
+	if total != res.Accepted {
+		t.Errorf("Bandit replay pulls mismatch: %v accepted: %v", res.Pulls, res.Accepted)
+	}
+	if res.Pulls[2] < res.Pulls[0] || res.Pulls[2] < res.Pulls[1] {
+		t.Errorf("Bandit replay didn't favour the best arm: %v", res.Pulls)
+	}
+	if res.Reward/float64(res.Accepted) <= logged/float64(len(events)) {
+		t.Errorf("Bandit replay reward %v not better than uniform %v", res.Reward/float64(res.Accepted), logged/float64(len(events)))
+	}
+}
@@ -40,7 +40,8 @@ func (b BetaBinomial) CDF(x float64) float64 {
 	for i := 0; i <= int(x); i++ {
 		cdf += b.Prob(float64(i))
 	}
-	return cdf
+	// Rounding in the sum can carry it past 1
+	return math.Min(cdf, 1)
 }
 
 // ExKurtosis returns the excess kurtosis of the distribution.
@@ -1,6 +1,7 @@
 package statext
 
 import (
+	"github.com/argusdusty/statext/disttest"
 	"golang.org/x/exp/rand"
 	"sort"
 	"testing"
@@ -24,13 +25,14 @@ func testBetaBinomial(t *testing.T, b BetaBinomial, i int) {
 		bins = 50
 	)
 	x := make([]float64, n)
-	generateSamples(x, b)
+	disttest.GenerateSamples(x, b)
 	sort.Float64s(x)
 
-	checkMean(t, i, x, b, tol)
-	checkSkewness(t, i, x, b, tol)
-	checkVarAndStd(t, i, x, b, tol)
-	checkExKurtosis(t, i, x, b, tol)
-	checkProbDiscrete(t, i, x, b, tol)
-	checkCDFSurvival(t, i, x, b, tol)
+	disttest.CheckMean(t, i, x, b, tol)
+	disttest.CheckSkewness(t, i, x, b, tol)
+	disttest.CheckVarAndStd(t, i, x, b, tol)
+	disttest.CheckExKurtosis(t, i, x, b, tol)
+	disttest.CheckRandLogProbDiscrete(t, i, x, b, 1e-3)
+	disttest.CheckProbDiscrete(t, i, x, b, tol)
+	disttest.CheckCDFSurvival(t, i, x, b, tol)
 }
@@ -2,7 +2,10 @@ package statext
 
 import (
 	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat"
 	"math"
 )
 
@@ -26,9 +29,63 @@ type BetaPrime struct {
 
 // CDF computes the value of the cumulative density function at x.
 func (b BetaPrime) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if math.IsInf(x, 1) {
+		return 1
+	}
+	if x*(b.Beta+1) > b.Alpha+1 {
+		// Past here RegIncBeta evaluates the complement at 1 - x/(1+x),
+		// losing the precision of 1/(1+x), so take the complement directly.
+		return 1 - mathext.RegIncBeta(b.Beta, b.Alpha, 1/(1+x))
+	}
 	return mathext.RegIncBeta(b.Alpha, b.Beta, x/(1+x))
 }
 
+// ConjugateUpdate updates the parameters of the distribution from the sufficient
+// statistics of a set of samples. The sufficient statistics, suffStat, have been
+// observed with nSamples observations. The prior values of the distribution are those
+// currently in the distribution, and have been observed with priorStrength samples.
+//
+// For the beta prime distribution, the sufficient statistics are the means of
+// log(x/(1+x)) and log(1/(1+x)), those of the Beta distribution of x/(1+x).
+// The prior is having seen priorStrength[0] samples with the expected sufficient
+// statistics of the current distribution. As a result of this function, Alpha and
+// Beta are updated to the maximum likelihood estimates given the combined
+// statistics, and priorStrength is modified to include the new number of samples
+// observed. If priorStrength[0] is 0, the current parameters are used only as the
+// starting point for the estimate, if they are valid.
+//
+// This function panics if len(suffStat) != 2 or len(priorStrength) != 1.
+func (b *BetaPrime) ConjugateUpdate(suffStat []float64, nSamples float64, priorStrength []float64) {
+	if len(suffStat) != 2 {
+		panic("beta prime: incorrect suffStat length")
+	}
+	if len(priorStrength) != 1 {
+		panic("beta prime: incorrect priorStrength length")
+	}
+	totalSamples := nSamples + priorStrength[0]
+	s1 := nSamples * suffStat[0]
+	s2 := nSamples * suffStat[1]
+	if priorStrength[0] != 0 {
+		dab := mathext.Digamma(b.Alpha + b.Beta)
+		s1 += priorStrength[0] * (mathext.Digamma(b.Alpha) - dab)
+		s2 += priorStrength[0] * (mathext.Digamma(b.Beta) - dab)
+	}
+	if !(b.Alpha > 0 && b.Beta > 0) || math.IsInf(b.Alpha, 0) || math.IsInf(b.Beta, 0) {
+		b.Alpha = 1
+		b.Beta = 1
+	}
+	b.Alpha, b.Beta = betaMLE(s1/totalSamples, s2/totalSamples, b.Alpha, b.Beta)
+	priorStrength[0] = totalSamples
+}
+
+// Entropy returns the differential entropy of the distribution.
+func (b BetaPrime) Entropy() float64 {
+	return mathext.Lbeta(b.Alpha, b.Beta) - (b.Alpha-1)*mathext.Digamma(b.Alpha) - (b.Beta+1)*mathext.Digamma(b.Beta) + (b.Alpha+b.Beta)*mathext.Digamma(b.Alpha+b.Beta)
+}
+
 // ExKurtosis returns the excess kurtosis of the distribution.
 //
 // ExKurtosis returns NaN if the Beta parameter is less or equal to 4.
@@ -39,12 +96,70 @@ func (b BetaPrime) ExKurtosis() float64 {
 	return 6 * (b.Alpha*(b.Alpha+b.Beta-1)*(5*b.Beta-11) + (b.Beta-1)*(b.Beta-1)*(b.Beta-2)) / (b.Alpha * (b.Alpha + b.Beta - 1) * (b.Beta - 3) * (b.Beta - 4))
 }
 
+// Fit sets the parameters of the probability distribution from the
+// data samples x with relative weights w.
+// If weights is nil, then all the weights are 1.
+// If weights is not nil, then the len(weights) must equal len(samples).
+//
+// Fit computes the maximum likelihood estimate, starting from the method of
+// moments estimate (which assumes Beta > 2, so that the variance exists) and
+// refining it by Newton's method on the Beta distribution of x/(1+x).
+func (b *BetaPrime) Fit(samples, weights []float64) {
+	suffStat := make([]float64, b.NumSuffStat())
+	nSamples := b.SuffStat(suffStat, samples, weights)
+	mean, std := stat.MeanStdDev(samples, weights)
+	b.Beta = 2 + mean*(mean+1)/(std*std)
+	b.Alpha = mean * (b.Beta - 1)
+	b.ConjugateUpdate(suffStat, nSamples, make([]float64, 1))
+}
+
+// LogCDF computes the natural logarithm of the value of the cumulative
+// density function at x. LogCDF remains accurate far into the left tail,
+// where CDF underflows to 0.
+func (b BetaPrime) LogCDF(x float64) float64 {
+	if x <= 0 {
+		return math.Inf(-1)
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	return logRegIncBeta(b.Alpha, b.Beta, x/(1+x))
+}
+
 // LogProb computes the natural logarithm of the value of the probability
 // density function at x.
+//
+// LogProb returns -Inf outside of the support (x < 0 or x = +Inf). At x = 0
+// the density is +Inf if Alpha < 1, Beta if Alpha == 1, and 0 otherwise.
 func (b BetaPrime) LogProb(x float64) float64 {
+	if x < 0 || math.IsInf(x, 1) {
+		return math.Inf(-1)
+	}
+	if x == 0 {
+		switch {
+		case b.Alpha < 1:
+			return math.Inf(1)
+		case b.Alpha == 1:
+			return math.Log(b.Beta)
+		}
+		return math.Inf(-1)
+	}
 	return math.Log(x)*(b.Alpha-1) + math.Log1p(x)*(-b.Alpha-b.Beta) - mathext.Lbeta(b.Alpha, b.Beta)
 }
 
+// LogSurvival computes the natural logarithm of the survival function
+// (complementary CDF) at x. LogSurvival remains accurate far into the right
+// tail, where Survival underflows to 0.
+func (b BetaPrime) LogSurvival(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if math.IsInf(x, 1) {
+		return math.Inf(-1)
+	}
+	return logRegIncBeta(b.Beta, b.Alpha, 1/(1+x))
+}
+
 // Mean returns the mean of the probability distribution.
 //
 // Mean returns NaN if the Beta parameter is less than or equal to 1.
@@ -55,6 +170,11 @@ func (b BetaPrime) Mean() float64 {
 	return b.Alpha / (b.Beta - 1)
 }
 
+// Median returns the median of the probability distribution.
+func (b BetaPrime) Median() float64 {
+	return b.Quantile(0.5)
+}
+
 // Mode returns the mode of the distribution.
 //
 // Mode returns NaN if the Beta parameter is less than or equal to 1.
@@ -70,6 +190,11 @@ func (b BetaPrime) NumParameters() int {
 	return 2
 }
 
+// NumSuffStat returns the number of sufficient statistics for the distribution.
+func (BetaPrime) NumSuffStat() int {
+	return 2
+}
+
 // Prob computes the value of the probability density function at x.
 func (b BetaPrime) Prob(x float64) float64 {
 	return math.Exp(b.LogProb(x))
@@ -80,16 +205,23 @@ func (b BetaPrime) Quantile(p float64) float64 {
 	if p < 0 || p > 1 {
 		panic("beta prime: bad percentile")
 	}
+	if p == 0 {
+		return 0
+	}
+	if p == 1 {
+		return math.Inf(1)
+	}
 	y := mathext.InvRegIncBeta(b.Alpha, b.Beta, p)
 	return y / (1 - y)
 }
 
 // Rand returns a random sample drawn from the distribution.
+//
+// The sample is generated as the ratio of two Gamma(Alpha, 1) and Gamma(Beta, 1)
+// variates. If Src is a *rand.Rand, it is used directly as the generator.
 func (b BetaPrime) Rand() float64 {
-	if b.Src != nil {
-		return b.Quantile(rand.New(b.Src).Float64())
-	}
-	return b.Quantile(rand.Float64())
+	rnd := newRander(b.Src)
+	return math.Exp(rnd.logGamma(b.Alpha) - rnd.logGamma(b.Beta))
 }
 
 // Skewness returns the skewness of the distribution.
@@ -112,9 +244,54 @@ func (b BetaPrime) StdDev() float64 {
 	return math.Sqrt(b.Variance())
 }
 
+// SuffStat computes the sufficient statistics of a set of samples to update
+// the distribution. The sufficient statistics are stored in place, and the
+// effective number of samples are returned.
+//
+// The beta prime distribution has two sufficient statistics, the weighted
+// means of log(x/(1+x)) and log(1/(1+x)).
+//
+// If weights is nil, the weights are assumed to be 1, otherwise panics if
+// len(samples) != len(weights). Panics if len(suffStat) != NumSuffStat().
+func (BetaPrime) SuffStat(suffStat, samples, weights []float64) (nSamples float64) {
+	if len(weights) != 0 && len(samples) != len(weights) {
+		panic("beta prime: slice length mismatch")
+	}
+	if len(suffStat) != (BetaPrime{}).NumSuffStat() {
+		panic("beta prime: wrong suffStat length")
+	}
+	var s1, s2 float64
+	for i, x := range samples {
+		w := 1.0
+		if len(weights) != 0 {
+			w = weights[i]
+		}
+		l := math.Log1p(x)
+		s1 += w * (math.Log(x) - l)
+		s2 -= w * l
+		nSamples += w
+	}
+	suffStat[0] = s1 / nSamples
+	suffStat[1] = s2 / nSamples
+	return nSamples
+}
+
 // Survival returns the survival function (complementary CDF) at x.
 func (b BetaPrime) Survival(x float64) float64 {
-	return 1 - b.CDF(x)
+	if x <= 0 {
+		return 1
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	if x*(b.Beta+1) <= b.Alpha+1 {
+		// As in CDF, the region where RegIncBeta would evaluate the
+		// complement at 1 - 1/(1+x).
+		return 1 - mathext.RegIncBeta(b.Alpha, b.Beta, x/(1+x))
+	}
+	// Computed directly from the complement rather than as 1 - CDF(x),
+	// so that the right tail doesn't cancel to 0.
+	return mathext.RegIncBeta(b.Beta, b.Alpha, 1/(1+x))
 }
 
 // Variance returns the variance of the probability distribution.
@@ -126,3 +303,470 @@ func (b BetaPrime) Variance() float64 {
 	}
 	return b.Alpha * (b.Alpha + b.Beta - 1) / ((b.Beta - 2) * (b.Beta - 1) * (b.Beta - 1))
 }
+
+// logRegIncBeta computes the natural logarithm of the regularized incomplete
+// beta function I_x(a, b). Where I_x(a, b) underflows, the continued fraction
+// expansion is evaluated directly with its prefactor kept in log space.
+func logRegIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return math.Inf(-1)
+	}
+	if x >= 1 {
+		return 0
+	}
+	v := mathext.RegIncBeta(a, b, x)
+	if v >= 1e-300 || x >= (a+1)/(a+b+2) {
+		// Either accurate as-is, or outside the region where the continued
+		// fraction converges quickly (and where I_x(a, b) can't be tiny).
+		return math.Log(v)
+	}
+	return a*math.Log(x) + b*math.Log1p(-x) - math.Log(a) - mathext.Lbeta(a, b) + math.Log(betaContinuedFraction(a, b, x))
+}
+
+// betaContinuedFraction evaluates the continued fraction for the incomplete
+// beta function using the modified Lentz's method, such that
+//  I_x(a, b) = x^a * (1-x)^b / (a * B(a, b)) * betaContinuedFraction(a, b, x)
+// For fast convergence, x should be less than (a+1)/(a+b+2).
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIter = 10000
+		eps     = 1e-16
+		tiny    = 1e-300
+	)
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		for _, aa := range [2]float64{
+			fm * (b - fm) * x / ((a + m2 - 1) * (a + m2)),
+			-(a + fm) * (a + b + fm) * x / ((a + m2) * (a + m2 + 1)),
+		} {
+			d = 1 + aa*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + aa/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// rander holds the generator used by the samplers.
+type rander struct {
+	rnd *rand.Rand // nil for the global source
+}
+
+// newRander returns a rander drawing from src, or from the global source if src
+// is nil. If src is already a *rand.Rand, it is used as-is. Otherwise it is
+// wrapped in a generator that holds no state beyond src, and doesn't escape,
+// so wrapping costs no allocation and each sampler can build its own.
+func newRander(src rand.Source) rander {
+	if src == nil {
+		return rander{}
+	}
+	rnd, ok := src.(*rand.Rand)
+	if !ok {
+		rnd = rand.New(src)
+	}
+	return rander{rnd}
+}
+
+// float64 returns a random sample drawn uniformly from [0, 1).
+func (r rander) float64() float64 {
+	if r.rnd == nil {
+		return rand.Float64()
+	}
+	return r.rnd.Float64()
+}
+
+// normFloat64 returns a random sample drawn from the standard normal
+// distribution.
+func (r rander) normFloat64() float64 {
+	if r.rnd == nil {
+		return rand.NormFloat64()
+	}
+	return r.rnd.NormFloat64()
+}
+
+// logGamma returns the logarithm of a random sample drawn from the
+// Gamma(a, 1) distribution, using the method of Marsaglia and Tsang:
+//  Marsaglia, George, and Wai Wan Tsang. "A simple method for generating gamma
+//  variables." ACM Transactions on Mathematical Software 26.3 (2000): 363-372.
+// The logarithm is returned so that samples with small a don't underflow.
+func (r rander) logGamma(a float64) float64 {
+	if a < 1 {
+		// Boost with Gamma(a) = Gamma(a+1) * U^(1/a)
+		u := r.float64()
+		for u == 0 {
+			u = r.float64()
+		}
+		return r.logGamma(a+1) + math.Log(u)/a
+	}
+	d := a - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.normFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.float64()
+		x2 := x * x
+		if u < 1-0.0331*x2*x2 || math.Log(u) < x2/2+d*(1-v+math.Log(v)) {
+			return math.Log(d * v)
+		}
+	}
+}
+
+// betaMLE returns the maximum likelihood estimates of the parameters of the
+// Beta distribution, given the means of log(y) and log(1-y) of the samples,
+// by Newton's method starting from (a, b). The log-likelihood is concave, so
+// the step-halving iterations converge from any valid starting point.
+func betaMLE(s1, s2, a, b float64) (float64, float64) {
+	const (
+		maxIter = 100
+		tol     = 1e-14
+	)
+	ll := func(a, b float64) float64 {
+		return (a-1)*s1 + (b-1)*s2 - mathext.Lbeta(a, b)
+	}
+	cur := ll(a, b)
+	for iter := 0; iter < maxIter; iter++ {
+		dab := mathext.Digamma(a + b)
+		ga := s1 - mathext.Digamma(a) + dab
+		gb := s2 - mathext.Digamma(b) + dab
+		tab := trigamma(a + b)
+		haa := tab - trigamma(a)
+		hbb := tab - trigamma(b)
+		det := haa*hbb - tab*tab
+		da := -(hbb*ga - tab*gb) / det
+		db := -(haa*gb - tab*ga) / det
+		step := 1.0
+		for a+step*da <= 0 || b+step*db <= 0 || ll(a+step*da, b+step*db) < cur {
+			step /= 2
+			if step < 1e-10 {
+				return a, b
+			}
+		}
+		a += step * da
+		b += step * db
+		cur = ll(a, b)
+		if math.Abs(step*da) <= tol*a && math.Abs(step*db) <= tol*b {
+			break
+		}
+	}
+	return a, b
+}
+
+// trigamma returns the trigamma function ψ'(x), the derivative of the digamma
+// function, for x > 0.
+func trigamma(x float64) float64 {
+	var r float64
+	// Shift x upwards with the recurrence ψ'(x) = ψ'(x+1) + 1/x^2,
+	// then apply the asymptotic expansion.
+	for x < 10 {
+		r += 1 / (x * x)
+		x++
+	}
+	x2 := 1 / (x * x)
+	return r + 1/x + x2/2 + x2/x*(1.0/6-x2*(1.0/30-x2*(1.0/42-x2*(1.0/30-x2*5/66))))
+}
+
+// GeneralizedBetaPrime implements the generalized Beta prime distribution, a
+// four-parameter continuous distribution with support over the positive real
+// numbers. If X follows a BetaPrime(α, β) distribution, then Q*X^(1/P)
+// follows a GeneralizedBetaPrime(α, β, P, Q) distribution.
+//
+// The generalized beta prime distribution has density function
+//  P * (x/Q)^(α*P-1) * (1+(x/Q)^P)^(-α-β) / (Q * B(α, β))
+//
+// For more information, see https://en.wikipedia.org/wiki/Beta_prime_distribution#Generalization
+type GeneralizedBetaPrime struct {
+	// Alpha is the left shape parameter of the distribution. Alpha must be greater
+	// than 0.
+	Alpha float64
+	// Beta is the right shape parameter of the distribution. Beta must be greater
+	// than 0.
+	Beta float64
+	// P is the power (shape) parameter of the distribution. P must be greater
+	// than 0. If P == 1, this is a scaled BetaPrime distribution.
+	P float64
+	// Q is the scale parameter of the distribution. Q must be greater than 0.
+	Q float64
+
+	Src rand.Source
+}
+
+// betaPrime returns the BetaPrime distribution of (X/Q)^P.
+func (g GeneralizedBetaPrime) betaPrime() BetaPrime {
+	return BetaPrime{Alpha: g.Alpha, Beta: g.Beta, Src: g.Src}
+}
+
+// standardize returns (x/Q)^P, the BetaPrime distributed value corresponding to x.
+func (g GeneralizedBetaPrime) standardize(x float64) float64 {
+	if x <= 0 {
+		return x
+	}
+	return math.Pow(x/g.Q, g.P)
+}
+
+// CDF computes the value of the cumulative density function at x.
+func (g GeneralizedBetaPrime) CDF(x float64) float64 {
+	return g.betaPrime().CDF(g.standardize(x))
+}
+
+// Entropy returns the differential entropy of the distribution.
+func (g GeneralizedBetaPrime) Entropy() float64 {
+	return g.betaPrime().Entropy() + math.Log(g.Q/g.P) + (1/g.P-1)*(mathext.Digamma(g.Alpha)-mathext.Digamma(g.Beta))
+}
+
+// ExKurtosis returns the excess kurtosis of the distribution.
+//
+// ExKurtosis returns NaN if Beta*P is less than or equal to 4.
+func (g GeneralizedBetaPrime) ExKurtosis() float64 {
+	m1 := g.rawMoment(1)
+	m2 := g.rawMoment(2)
+	m3 := g.rawMoment(3)
+	m4 := g.rawMoment(4)
+	v := m2 - m1*m1
+	return (m4-4*m1*m3+6*m1*m1*m2-3*m1*m1*m1*m1)/(v*v) - 3
+}
+
+// Fit sets the parameters of the probability distribution from the
+// data samples x with relative weights w, by maximizing the likelihood.
+// If weights is nil, then all the weights are 1.
+// If weights is not nil, then the len(weights) must equal len(samples).
+// All samples must be greater than 0.
+//
+// The current parameters are used as the starting point of the optimization
+// if they are all positive. Otherwise, the optimization starts from Alpha = 1,
+// Beta = 1, P = 1, with Q equal to the weighted median of the samples.
+func (g *GeneralizedBetaPrime) Fit(samples, weights []float64) {
+	if weights != nil && len(samples) != len(weights) {
+		panic("generalized beta prime: slice length mismatch")
+	}
+	if len(samples) == 0 {
+		panic("generalized beta prime: must have at least one sample")
+	}
+	logx := make([]float64, len(samples))
+	for i, x := range samples {
+		if !(x > 0) || math.IsInf(x, 1) {
+			panic("generalized beta prime: sample out of support")
+		}
+		logx[i] = math.Log(x)
+	}
+	init := []float64{g.Alpha, g.Beta, g.P, g.Q}
+	if !(g.Alpha > 0 && g.Beta > 0 && g.P > 0 && g.Q > 0) {
+		sorted := make([]float64, len(samples))
+		copy(sorted, samples)
+		var sortedWeights []float64
+		if weights != nil {
+			sortedWeights = make([]float64, len(weights))
+			copy(sortedWeights, weights)
+		}
+		stat.SortWeighted(sorted, sortedWeights)
+		init = []float64{1, 1, 1, stat.Quantile(0.5, stat.Empirical, sorted, sortedWeights)}
+	}
+	for i := range init {
+		init[i] = math.Log(init[i])
+	}
+	var sumWeights float64
+	if weights == nil {
+		sumWeights = float64(len(samples))
+	} else {
+		sumWeights = floats.Sum(weights)
+	}
+	// Optimize over the logs of the parameters to keep them positive.
+	// The objective is the negative mean log-likelihood.
+	problem := optimize.Problem{
+		Func: func(theta []float64) float64 {
+			a, b, p, q := math.Exp(theta[0]), math.Exp(theta[1]), math.Exp(theta[2]), math.Exp(theta[3])
+			lq := math.Log(q)
+			var ll float64
+			for i, lx := range logx {
+				u := lx - lq
+				w := 1.0
+				if weights != nil {
+					w = weights[i]
+				}
+				ll += w * ((a*p-1)*u - (a+b)*log1pExp(p*u))
+			}
+			ll /= sumWeights
+			ll += math.Log(p) - lq - mathext.Lbeta(a, b)
+			return -ll
+		},
+		Grad: func(grad, theta []float64) {
+			a, b, p, q := math.Exp(theta[0]), math.Exp(theta[1]), math.Exp(theta[2]), math.Exp(theta[3])
+			lq := math.Log(q)
+			var su, sl, sw, swu float64
+			for i, lx := range logx {
+				u := lx - lq
+				w := 1.0
+				if weights != nil {
+					w = weights[i]
+				}
+				// l = log(1 + z), r = z/(1+z), where z = (x/q)^p
+				l := log1pExp(p * u)
+				r := math.Exp(p*u - l)
+				su += w * u
+				sl += w * l
+				sw += w * r
+				swu += w * r * u
+			}
+			su /= sumWeights
+			sl /= sumWeights
+			sw /= sumWeights
+			swu /= sumWeights
+			dab := mathext.Digamma(a + b)
+			grad[0] = -a * (p*su - sl - mathext.Digamma(a) + dab)
+			grad[1] = -b * (-sl - mathext.Digamma(b) + dab)
+			grad[2] = -p * (1/p + a*su - (a+b)*swu)
+			grad[3] = -(-a*p + (a+b)*p*sw)
+		},
+	}
+	result, err := optimize.Minimize(problem, init, nil, nil)
+	if result == nil {
+		panic(err)
+	}
+	g.Alpha = math.Exp(result.X[0])
+	g.Beta = math.Exp(result.X[1])
+	g.P = math.Exp(result.X[2])
+	g.Q = math.Exp(result.X[3])
+}
+
+// LogCDF computes the natural logarithm of the value of the cumulative
+// density function at x.
+func (g GeneralizedBetaPrime) LogCDF(x float64) float64 {
+	return g.betaPrime().LogCDF(g.standardize(x))
+}
+
+// LogProb computes the natural logarithm of the value of the probability
+// density function at x.
+func (g GeneralizedBetaPrime) LogProb(x float64) float64 {
+	if x < 0 || math.IsInf(x, 1) {
+		return math.Inf(-1)
+	}
+	ap := g.Alpha * g.P
+	if x == 0 {
+		switch {
+		case ap < 1:
+			return math.Inf(1)
+		case ap == 1:
+			return math.Log(g.P/g.Q) - mathext.Lbeta(g.Alpha, g.Beta)
+		}
+		return math.Inf(-1)
+	}
+	u := math.Log(x / g.Q)
+	return math.Log(g.P/g.Q) + (ap-1)*u - (g.Alpha+g.Beta)*log1pExp(g.P*u) - mathext.Lbeta(g.Alpha, g.Beta)
+}
+
+// LogSurvival computes the natural logarithm of the survival function
+// (complementary CDF) at x.
+func (g GeneralizedBetaPrime) LogSurvival(x float64) float64 {
+	return g.betaPrime().LogSurvival(g.standardize(x))
+}
+
+// Mean returns the mean of the probability distribution.
+//
+// Mean returns NaN if Beta*P is less than or equal to 1.
+func (g GeneralizedBetaPrime) Mean() float64 {
+	return g.rawMoment(1)
+}
+
+// Median returns the median of the probability distribution.
+func (g GeneralizedBetaPrime) Median() float64 {
+	return g.Quantile(0.5)
+}
+
+// Mode returns the mode of the distribution.
+func (g GeneralizedBetaPrime) Mode() float64 {
+	if g.Alpha*g.P < 1 {
+		return 0
+	}
+	return g.Q * math.Pow((g.Alpha*g.P-1)/(g.Beta*g.P+1), 1/g.P)
+}
+
+// NumParameters returns the number of parameters in the distribution.
+func (GeneralizedBetaPrime) NumParameters() int {
+	return 4
+}
+
+// Prob computes the value of the probability density function at x.
+func (g GeneralizedBetaPrime) Prob(x float64) float64 {
+	return math.Exp(g.LogProb(x))
+}
+
+// Quantile returns the inverse of the cumulative distribution function.
+func (g GeneralizedBetaPrime) Quantile(p float64) float64 {
+	if p < 0 || p > 1 {
+		panic("generalized beta prime: bad percentile")
+	}
+	return g.Q * math.Pow(g.betaPrime().Quantile(p), 1/g.P)
+}
+
+// Rand returns a random sample drawn from the distribution.
+func (g GeneralizedBetaPrime) Rand() float64 {
+	return g.Q * math.Pow(g.betaPrime().Rand(), 1/g.P)
+}
+
+// rawMoment returns E[X^k], or NaN if Beta*P is less than or equal to k.
+func (g GeneralizedBetaPrime) rawMoment(k float64) float64 {
+	if g.Beta*g.P <= k {
+		return math.NaN()
+	}
+	return math.Pow(g.Q, k) * math.Exp(mathext.Lbeta(g.Alpha+k/g.P, g.Beta-k/g.P)-mathext.Lbeta(g.Alpha, g.Beta))
+}
+
+// Skewness returns the skewness of the distribution.
+//
+// Skewness returns NaN if Beta*P is less than or equal to 3.
+func (g GeneralizedBetaPrime) Skewness() float64 {
+	m1 := g.rawMoment(1)
+	m2 := g.rawMoment(2)
+	m3 := g.rawMoment(3)
+	v := m2 - m1*m1
+	return (m3 - 3*m1*m2 + 2*m1*m1*m1) / (v * math.Sqrt(v))
+}
+
+// StdDev returns the standard deviation of the probability distribution.
+//
+// StdDev returns NaN if Beta*P is less than or equal to 2.
+func (g GeneralizedBetaPrime) StdDev() float64 {
+	return math.Sqrt(g.Variance())
+}
+
+// Survival returns the survival function (complementary CDF) at x.
+func (g GeneralizedBetaPrime) Survival(x float64) float64 {
+	return g.betaPrime().Survival(g.standardize(x))
+}
+
+// Variance returns the variance of the probability distribution.
+//
+// Variance returns NaN if Beta*P is less than or equal to 2.
+func (g GeneralizedBetaPrime) Variance() float64 {
+	m1 := g.rawMoment(1)
+	return g.rawMoment(2) - m1*m1
+}
+
+// log1pExp computes log(1 + exp(x)) without overflow for large x.
+func log1pExp(x float64) float64 {
+	if x > 0 {
+		return x + math.Log1p(math.Exp(-x))
+	}
+	return math.Log1p(math.Exp(x))
+}
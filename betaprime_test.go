@@ -1,7 +1,10 @@
 package statext
 
 import (
+	"github.com/argusdusty/statext/disttest"
 	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mathext"
+	"math"
 	"sort"
 	"testing"
 )
@@ -24,14 +27,340 @@ func testBetaPrime(t *testing.T, b BetaPrime, i int) {
 		bins = 50
 	)
 	x := make([]float64, n)
-	generateSamples(x, b)
+	disttest.GenerateSamples(x, b)
 	sort.Float64s(x)
 
-	testRandLogProbContinuous(t, i, 0, x, b, tol, bins)
-	checkProbContinuous(t, i, x, b, 1e-3)
-	checkMean(t, i, x, b, tol)
-	checkVarAndStd(t, i, x, b, tol)
-	checkExKurtosis(t, i, x, b, 1e-1)
-	checkSkewness(t, i, x, b, 5e-2)
-	checkQuantileCDFSurvival(t, i, x, b, 5e-3)
+	disttest.CheckRandLogProbContinuous(t, i, 0, x, b, tol, bins)
+	disttest.CheckProbContinuous(t, i, x, b, 1e-3)
+	disttest.CheckMean(t, i, x, b, tol)
+	disttest.CheckVarAndStd(t, i, x, b, tol)
+	disttest.CheckExKurtosis(t, i, x, b, 1e-1)
+	disttest.CheckSkewness(t, i, x, b, 5e-2)
+	disttest.CheckQuantileCDFSurvival(t, i, x, b, 5e-3)
+	disttest.CheckEntropy(t, i, x, b, tol)
+	disttest.CheckMedian(t, i, x, b, tol)
+}
+
+func TestBetaPrimeSmallShapes(t *testing.T) {
+	// Samples from tiny shape parameters must stay within the support.
+	src := rand.New(rand.NewSource(1))
+	for i, b := range []BetaPrime{
+		{0.01, 0.01, src},
+		{1e-3, 5, src},
+		{5, 1e-3, src},
+	} {
+		for j := 0; j < 1000; j++ {
+			if x := b.Rand(); !(x >= 0) {
+				t.Fatalf("Rand out of support. Case %v. Got %v", i, x)
+			}
+		}
+	}
+}
+
+func TestBetaPrimeRandAllocs(t *testing.T) {
+	// Wrapping a Source that isn't a *rand.Rand must not allocate per sample.
+	for _, src := range []rand.Source{nil, rand.NewSource(1), rand.New(rand.NewSource(1))} {
+		b := BetaPrime{Alpha: 0.5, Beta: 3, Src: src}
+		allocs := testing.AllocsPerRun(100, func() {
+			b.Rand()
+		})
+		if allocs != 0 {
+			t.Errorf("BetaPrime Rand allocated %v times with source %T, want 0", allocs, src)
+		}
+	}
+}
+
+func TestBetaPrimeBoundaries(t *testing.T) {
+	for i, test := range []struct {
+		b    BetaPrime
+		prob float64 // Density at x = 0
+	}{
+		{BetaPrime{Alpha: 0.5, Beta: 2}, math.Inf(1)},
+		{BetaPrime{Alpha: 1, Beta: 2}, 2},
+		{BetaPrime{Alpha: 1, Beta: 0.5}, 0.5},
+		{BetaPrime{Alpha: 3, Beta: 2}, 0},
+	} {
+		b := test.b
+		for _, x := range []float64{-1, -1e-300, math.Inf(-1), math.Inf(1)} {
+			if !math.IsInf(b.LogProb(x), -1) {
+				t.Errorf("LogProb out-of-bounds mismatch. Case %v at %v. Got %v, want %v", i, x, b.LogProb(x), math.Inf(-1))
+			}
+			if b.Prob(x) != 0 {
+				t.Errorf("Prob out-of-bounds mismatch. Case %v at %v. Got %v, want 0", i, x, b.Prob(x))
+			}
+		}
+		if p := b.Prob(0); math.Abs(p-test.prob) > 1e-14 && p != test.prob {
+			t.Errorf("Prob mismatch at 0. Case %v. Got %v, want %v", i, p, test.prob)
+		}
+		for _, x := range []float64{math.Inf(-1), -1, 0} {
+			if b.CDF(x) != 0 || b.Survival(x) != 1 {
+				t.Errorf("CDF/Survival mismatch. Case %v at %v. Got %v/%v, want 0/1", i, x, b.CDF(x), b.Survival(x))
+			}
+		}
+		if x := math.Inf(1); b.CDF(x) != 1 || b.Survival(x) != 0 {
+			t.Errorf("CDF/Survival mismatch. Case %v at %v. Got %v/%v, want 1/0", i, x, b.CDF(x), b.Survival(x))
+		}
+		if q := b.Quantile(0); q != 0 {
+			t.Errorf("Quantile mismatch. Case %v at 0. Got %v, want 0", i, q)
+		}
+		if q := b.Quantile(1); !math.IsInf(q, 1) {
+			t.Errorf("Quantile mismatch. Case %v at 1. Got %v, want %v", i, q, math.Inf(1))
+		}
+	}
+}
+
+func TestBetaPrimeTails(t *testing.T) {
+	const tol = 1e-12
+	for i, test := range []struct {
+		b           BetaPrime
+		x           float64
+		logCDF      float64
+		logSurvival float64
+	}{
+		// With Alpha == 1, Survival(x) = (1+x)^-Beta.
+		{BetaPrime{Alpha: 1, Beta: 5}, 1e20, math.Log1p(-math.Pow(1+1e20, -5)), -5 * math.Log1p(1e20)},
+		{BetaPrime{Alpha: 1, Beta: 40}, 1e10, math.Log1p(-math.Pow(1+1e10, -40)), -40 * math.Log1p(1e10)},
+		// With Beta == 1, CDF(x) = (x/(1+x))^Alpha.
+		{BetaPrime{Alpha: 50, Beta: 1}, 1e-10, 50 * (math.Log(1e-10) - math.Log1p(1e-10)), math.Log1p(-math.Pow(1e-10/(1+1e-10), 50))},
+		// With Alpha == 2, Survival(x) = (1+x)^-Beta * (1 + Beta*x/(1+x)).
+		{BetaPrime{Alpha: 2, Beta: 300}, 100, math.Log1p(-math.Pow(101, -300) * (1 + 300*100.0/101)), -300*math.Log(101) + math.Log(1+300*100.0/101)},
+		// With Beta == 2, CDF(x) = (x/(1+x))^Alpha * (1 + Alpha/(1+x)).
+		{BetaPrime{Alpha: 300, Beta: 2}, 0.01, -300*math.Log(101) + math.Log(1+300/1.01), math.Log1p(-math.Pow(101, -300) * (1 + 300/1.01))},
+	} {
+		b := test.b
+		if v := b.LogCDF(test.x); math.Abs(v-test.logCDF) > tol*math.Max(1, math.Abs(test.logCDF)) {
+			t.Errorf("LogCDF mismatch. Case %v. Got %v, want %v", i, v, test.logCDF)
+		}
+		if v := b.LogSurvival(test.x); math.Abs(v-test.logSurvival) > tol*math.Max(1, math.Abs(test.logSurvival)) {
+			t.Errorf("LogSurvival mismatch. Case %v. Got %v, want %v", i, v, test.logSurvival)
+		}
+		if s, want := b.Survival(test.x), math.Exp(test.logSurvival); math.Abs(s-want) > tol*want {
+			t.Errorf("Survival mismatch. Case %v. Got %v, want %v", i, s, want)
+		}
+	}
+}
+
+func TestBetaContinuedFraction(t *testing.T) {
+	const tol = 1e-13
+	for i, test := range [][3]float64{
+		{2, 3, 0.1},
+		{0.5, 0.5, 0.2},
+		{10, 20, 0.3},
+		{300, 2, 0.5},
+	} {
+		a, b, x := test[0], test[1], test[2]
+		want := math.Log(mathext.RegIncBeta(a, b, x))
+		got := a*math.Log(x) + b*math.Log1p(-x) - math.Log(a) - mathext.Lbeta(a, b) + math.Log(betaContinuedFraction(a, b, x))
+		if math.Abs(got-want) > tol*math.Max(1, math.Abs(want)) {
+			t.Errorf("Continued fraction mismatch. Case %v. Got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestGeneralizedBetaPrime(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for i, g := range []GeneralizedBetaPrime{
+		{12, 16, 1.5, 2, src},
+		{3, 12, 0.8, 0.5, src},
+	} {
+		testGeneralizedBetaPrime(t, g, i)
+	}
+}
+
+func testGeneralizedBetaPrime(t *testing.T, g GeneralizedBetaPrime, i int) {
+	const (
+		tol  = 1e-2
+		n    = 1e6
+		bins = 50
+	)
+	x := make([]float64, n)
+	disttest.GenerateSamples(x, g)
+	sort.Float64s(x)
+
+	disttest.CheckRandLogProbContinuous(t, i, 0, x, g, tol, bins)
+	disttest.CheckProbContinuous(t, i, x, g, 1e-3)
+	disttest.CheckMean(t, i, x, g, tol)
+	disttest.CheckVarAndStd(t, i, x, g, tol)
+	disttest.CheckExKurtosis(t, i, x, g, 1e-1)
+	disttest.CheckSkewness(t, i, x, g, 5e-2)
+	disttest.CheckQuantileCDFSurvival(t, i, x, g, 5e-3)
+	disttest.CheckEntropy(t, i, x, g, tol)
+	disttest.CheckMedian(t, i, x, g, tol)
+}
+
+func TestGeneralizedBetaPrimeReduces(t *testing.T) {
+	const tol = 1e-12
+	for i, b := range []BetaPrime{
+		{0.5, 2, nil},
+		{3, 4.5, nil},
+		{12, 16, nil},
+	} {
+		g := GeneralizedBetaPrime{Alpha: b.Alpha, Beta: b.Beta, P: 1, Q: 1}
+		for _, x := range []float64{-1, 0, 0.01, 0.5, 1, 2, 100} {
+			for _, f := range []struct {
+				name string
+				b, g func(float64) float64
+			}{
+				{"LogProb", b.LogProb, g.LogProb},
+				{"CDF", b.CDF, g.CDF},
+				{"Survival", b.Survival, g.Survival},
+				{"LogCDF", b.LogCDF, g.LogCDF},
+				{"LogSurvival", b.LogSurvival, g.LogSurvival},
+			} {
+				want, got := f.b(x), f.g(x)
+				if want != got && math.Abs(want-got) > tol*math.Max(1, math.Abs(want)) {
+					t.Errorf("%v mismatch with BetaPrime. Case %v at %v. Got %v, want %v", f.name, i, x, got, want)
+				}
+			}
+		}
+		for _, p := range []float64{0, 0.1, 0.5, 0.9} {
+			if want, got := b.Quantile(p), g.Quantile(p); math.Abs(want-got) > tol*math.Max(1, want) {
+				t.Errorf("Quantile mismatch with BetaPrime. Case %v at %v. Got %v, want %v", i, p, got, want)
+			}
+		}
+		if b.Beta > 4 {
+			for _, f := range []struct {
+				name string
+				b, g float64
+			}{
+				{"Mean", b.Mean(), g.Mean()},
+				{"Variance", b.Variance(), g.Variance()},
+				{"Skewness", b.Skewness(), g.Skewness()},
+				{"ExKurtosis", b.ExKurtosis(), g.ExKurtosis()},
+			} {
+				if math.Abs(f.b-f.g) > 1e-10*math.Abs(f.b) {
+					t.Errorf("%v mismatch with BetaPrime. Case %v. Got %v, want %v", f.name, i, f.g, f.b)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneralizedBetaPrimeFit(t *testing.T) {
+	const (
+		n   = 1e5
+		tol = 0.1
+	)
+	src := rand.New(rand.NewSource(1))
+	for i, want := range []GeneralizedBetaPrime{
+		{2, 3, 1.5, 2, src},
+		{4, 2, 0.7, 0.5, src},
+	} {
+		x := make([]float64, n)
+		disttest.GenerateSamples(x, want)
+		var got GeneralizedBetaPrime
+		got.Fit(x, nil)
+		// The fitted parameters must be at least as likely as the true ones.
+		var llGot, llWant float64
+		for _, v := range x {
+			llGot += got.LogProb(v)
+			llWant += want.LogProb(v)
+		}
+		if llGot < llWant-1e-6*math.Abs(llWant) {
+			t.Errorf("Fit log-likelihood less than truth. Case %v. Got %v, want at least %v", i, llGot, llWant)
+		}
+		for _, p := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"Alpha", got.Alpha, want.Alpha},
+			{"Beta", got.Beta, want.Beta},
+			{"P", got.P, want.P},
+			{"Q", got.Q, want.Q},
+		} {
+			if math.Abs(p.got-p.want) > tol*p.want {
+				t.Errorf("Fit %v mismatch. Case %v. Got %v, want %v", p.name, i, p.got, p.want)
+			}
+		}
+	}
+}
+
+func TestBetaPrimeFit(t *testing.T) {
+	const (
+		n   = 1e5
+		tol = 5e-2
+	)
+	src := rand.New(rand.NewSource(1))
+	for i, want := range []BetaPrime{
+		{2, 3, src},
+		{12, 16, src},
+		{0.5, 1.5, src},
+	} {
+		x := make([]float64, n)
+		disttest.GenerateSamples(x, want)
+		var got BetaPrime
+		got.Fit(x, nil)
+		if math.Abs(got.Alpha-want.Alpha) > tol*want.Alpha || math.Abs(got.Beta-want.Beta) > tol*want.Beta {
+			t.Errorf("Fit mismatch. Case %v. Got (%v, %v), want (%v, %v)", i, got.Alpha, got.Beta, want.Alpha, want.Beta)
+		}
+
+		// Weighting a sample by 2 is the same as including it twice.
+		w := make([]float64, len(x)/2)
+		for j := range w {
+			w[j] = 1
+		}
+		w[0] = 2
+		var wgot, dgot BetaPrime
+		wgot.Fit(x[:len(w)], w)
+		dgot.Fit(append([]float64{x[0]}, x[:len(w)]...), nil)
+		if math.Abs(wgot.Alpha-dgot.Alpha) > 1e-8*dgot.Alpha || math.Abs(wgot.Beta-dgot.Beta) > 1e-8*dgot.Beta {
+			t.Errorf("Weighted Fit mismatch. Case %v. Got (%v, %v), want (%v, %v)", i, wgot.Alpha, wgot.Beta, dgot.Alpha, dgot.Beta)
+		}
+	}
+}
+
+func TestBetaPrimeConjugateUpdate(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	truth := BetaPrime{3, 4, src}
+	x := make([]float64, 1000)
+	disttest.GenerateSamples(x, truth)
+
+	b := BetaPrime{Alpha: 3, Beta: 4}
+	suffStat := make([]float64, b.NumSuffStat())
+	nSamples := b.SuffStat(suffStat, x, nil)
+	priorStrength := []float64{1e9}
+	b.ConjugateUpdate(suffStat, nSamples, priorStrength)
+	if priorStrength[0] != 1e9+1000 {
+		t.Errorf("priorStrength mismatch. Got %v, want %v", priorStrength[0], 1e9+1000)
+	}
+	// A very strong prior at the truth should barely move.
+	if math.Abs(b.Alpha-3) > 1e-3 || math.Abs(b.Beta-4) > 1e-3 {
+		t.Errorf("Strong prior moved too far. Got (%v, %v), want (3, 4)", b.Alpha, b.Beta)
+	}
+
+	// Splitting the samples across two updates matches fitting them all at once.
+	var all, split BetaPrime
+	all.Fit(x, nil)
+	split.Fit(x[:500], nil)
+	nSamples = split.SuffStat(suffStat, x[500:], nil)
+	split.ConjugateUpdate(suffStat, nSamples, []float64{500})
+	if math.Abs(all.Alpha-split.Alpha) > 1e-8*all.Alpha || math.Abs(all.Beta-split.Beta) > 1e-8*all.Beta {
+		t.Errorf("Sequential update mismatch. Got (%v, %v), want (%v, %v)", split.Alpha, split.Beta, all.Alpha, all.Beta)
+	}
+}
+
+func TestTrigamma(t *testing.T) {
+	const tol = 1e-12
+	// ψ'(1) = π²/6, ψ'(1/2) = π²/2, and ψ'(x+1) = ψ'(x) - 1/x².
+	want := math.Pi * math.Pi / 6
+	for n := 1.0; n <= 20; n++ {
+		if got := trigamma(n); math.Abs(got-want) > tol*want {
+			t.Errorf("Trigamma mismatch at %v. Got %v, want %v", n, got, want)
+		}
+		want -= 1 / (n * n)
+	}
+	want = math.Pi * math.Pi / 2
+	for x := 0.5; x <= 20; x++ {
+		if got := trigamma(x); math.Abs(got-want) > tol*want {
+			t.Errorf("Trigamma mismatch at %v. Got %v, want %v", x, got, want)
+		}
+		want -= 1 / (x * x)
+	}
+}
+
+func BenchmarkBetaPrimeRand(b *testing.B) {
+	d := BetaPrime{12, 16, rand.New(rand.NewSource(1))}
+	for i := 0; i < b.N; i++ {
+		d.Rand()
+	}
 }
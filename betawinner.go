@@ -0,0 +1,133 @@
+package statext
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// betaWinnerAdaptiveQuadFunc is the function to integrate for the
+// beta winner probs. The first n entries of result are the integrands
+// for the probabilities, and entry n is the integrand for the expected
+// value of the maximum, x times their sum, as in DirichletWinnerLoss.
+// As in dirichletWinnerAdaptiveQuadFunc, y is mapped to
+// u = logit(x) = mu + sigma*logit(y), and the integrand is evaluated in log
+// space, with x and 1-x each computed directly from u. The x^(α-1) and
+// (1-x)^(β-1) endpoint singularities become exponential tails in u, so
+// shape parameters below 1 need no special treatment.
+// Works in-place on result array.
+func betaWinnerAdaptiveQuadFunc(mu, sigma, y float64, alphas, betas, result, lbetas []float64) {
+	n := len(alphas)
+	if y == 0.0 || y == 1.0 {
+		for j := range result {
+			result[j] = 0.0
+		}
+		return
+	}
+	logY, log1mY := math.Log(y), math.Log1p(-y)
+	u := mu + sigma*(logY-log1mY)
+	logJac := math.Log(sigma) - logY - log1mY
+	// log(x) and log(1-x), for x = 1/(1+e^-u).
+	logX, log1mX := -softplus(-u), -softplus(u)
+	x, x1m := math.Exp(logX), math.Exp(log1mX)
+	var logCDFs float64
+	// Computes beta(alphas[j], betas[j]).pdf(x)*product(beta(alphas[i], betas[i]).cdf(x), i!=j)*dx/dy
+	// for each j in the same manner as dirichletWinnerAdaptiveQuadFunc, with
+	// log(beta(alpha, beta).pdf(x)*dx/du) = alpha*log(x) + beta*log(1-x) - lbeta.
+	for j, alpha := range alphas {
+		var c float64
+		if x <= alpha/(alpha+betas[j]) {
+			c = math.Log(mathext.RegIncBeta(alpha, betas[j], x))
+		} else {
+			// Take the cdf as the complement of the survival function, so
+			// that it keeps its accuracy near x = 1.
+			c = math.Log1p(-mathext.RegIncBeta(betas[j], alpha, x1m))
+		}
+		if math.IsInf(c, -1) {
+			// The cdf underflows, so the whole product is negligible.
+			for k := 0; k < n; k++ {
+				result[k] = 0.0
+			}
+			result[n] = 0.0
+			return
+		}
+		result[j] = alpha*logX + betas[j]*log1mX - lbetas[j] - c
+		logCDFs += c
+	}
+	result[n] = 0.0
+	for j := 0; j < n; j++ {
+		result[j] = math.Exp(result[j] + logCDFs + logJac)
+		result[n] += x * result[j]
+	}
+}
+
+// softplus returns log(1+e^u), without overflow for large u.
+func softplus(u float64) float64 {
+	if u > 0 {
+		return u + math.Log1p(math.Exp(-u))
+	}
+	return math.Log1p(math.Exp(u))
+}
+
+// betaWinnerPrepare returns the centre mu and scale sigma of the change of
+// variables of betaWinnerAdaptiveQuadFunc. As for dirichletWinnerPrepare,
+// the integrand is concentrated where the leading outputs compete, so it is
+// centred on the mean and standard deviation of logit(X) for X Beta
+// distributed with the largest mean. As y -> 0 the integrand behaves as
+// y^(sigma*sum(alphas)-1), and as y -> 1 as y^(sigma*min(betas)-1), so sigma
+// is kept large enough for both to vanish smoothly.
+func betaWinnerPrepare(alphas, betas []float64) (mu, sigma float64) {
+	best, sum, minBeta := 0, 0.0, betas[0]
+	for j, alpha := range alphas {
+		if alpha/(alpha+betas[j]) > alphas[best]/(alphas[best]+betas[best]) {
+			best = j
+		}
+		sum += alpha
+		minBeta = math.Min(minBeta, betas[j])
+	}
+	a, b := alphas[best], betas[best]
+	mu = mathext.Digamma(a) - mathext.Digamma(b)
+	sigma = math.Sqrt(trigamma(a) + trigamma(b))
+	return mu, math.Max(sigma, math.Max(2/sum, 2/minBeta))
+}
+
+// BetaWinner computes the probabilities that each of the independent
+// Beta(alphas[i], betas[i]) distributed values will be the largest,
+// as in Thompson sampling for Bernoulli bandits or A/B/n testing.
+// It also computes the expected loss (or "value remaining") of each,
+// E[max_j X_j - X_i], the expected shortfall from choosing i over the
+// largest.
+// Uses the same adaptive quadrature integration technique as DirichletWinner.
+// BetaWinner returns nil slices if tol is NaN or less than
+// MinDirichletWinnerTol, and panics if len(alphas) != len(betas).
+func BetaWinner(alphas, betas []float64, tol float64) (probs, loss []float64) {
+	n := len(alphas)
+	if len(betas) != n {
+		panic("beta winner: slice length mismatch")
+	}
+	if !(tol >= MinDirichletWinnerTol) {
+		return nil, nil
+	}
+	switch n {
+	case 0:
+		return []float64{}, []float64{}
+	case 1:
+		return []float64{1.0}, []float64{0.0}
+	}
+	lbetas := make([]float64, n)
+	for j, alpha := range alphas {
+		lbetas[j] = mathext.Lbeta(alpha, betas[j])
+	}
+	mu, sigma := betaWinnerPrepare(alphas, betas)
+	q := newAdaptiveQuad(func(y float64, dst []float64) {
+		betaWinnerAdaptiveQuadFunc(mu, sigma, y, alphas, betas, dst, lbetas)
+	}, n+1, tol)
+	q.integrate(n, 1, float64(2*n)*tol)
+	probs = q.result[:n:n]
+	loss = make([]float64, n)
+	for i, alpha := range alphas {
+		// E[max_j X_j] - E[X_i]
+		loss[i] = math.Max(q.result[n]-alpha/(alpha+betas[i]), 0)
+	}
+	return probs, loss
+}
@@ -0,0 +1,152 @@
+package statext
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// betaBeatsProb computes P(X_b > X_a) for X_a ~ Beta(aa, ba), X_b ~ Beta(ab, bb),
+// with integer ab, using the closed form summation.
+func betaBeatsProb(aa, ba, ab, bb float64) float64 {
+	var p float64
+	for i := 0.0; i < ab; i++ {
+		p += math.Exp(mathext.Lbeta(aa+i, ba+bb) - math.Log(bb+i) - mathext.Lbeta(1+i, bb) - mathext.Lbeta(aa, ba))
+	}
+	return p
+}
+
+func TestBetaWinner(t *testing.T) {
+	for _, test := range []struct {
+		alphas, betas []float64
+	}{
+		{[]float64{1, 1}, []float64{1, 1}},
+		{[]float64{3, 5}, []float64{7, 6}},
+		{[]float64{41, 52}, []float64{960, 950}},
+		{[]float64{0.5, 2}, []float64{2, 0.5}},
+	} {
+		a, b := test.alphas, test.betas
+		want := betaBeatsProb(a[0], b[0], math.Ceil(a[1]), b[1])
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			p, _ := BetaWinner(a, b, tol)
+			if a[1] == math.Floor(a[1]) && math.Abs(p[1]-want) > 2*tol {
+				t.Errorf("BetaWinner mismatch. alphas: %v betas: %v tol: %g got: %v want: %v", a, b, tol, p[1], want)
+			}
+			if math.Abs(p[0]+p[1]-1) > 4*tol {
+				t.Errorf("BetaWinner large sum error: %g tol: %g", 1-p[0]-p[1], tol)
+			}
+		}
+	}
+
+	// Shape parameters below 1 give the pdfs integrable singularities at the
+	// endpoints, which must not cost accuracy.
+	for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+		p, _ := BetaWinner([]float64{0.2, 3}, []float64{5, 0.3}, tol)
+		if want := betaBeatsProb(0.2, 5, 3, 0.3); math.Abs(p[1]-want) > 2*tol {
+			t.Errorf("BetaWinner mismatch with small shapes. tol: %g got: %v want: %v", tol, p[1], want)
+		}
+	}
+	for _, test := range []struct {
+		alphas, betas []float64
+	}{
+		{[]float64{0.1, 0.2, 0.05}, []float64{0.1, 0.3, 0.5}},
+		{[]float64{0.01, 0.02}, []float64{0.01, 0.05}},
+		{[]float64{0.5, 0.9, 0.3, 2}, []float64{0.7, 0.2, 0.05, 4}},
+	} {
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			p, _ := BetaWinner(test.alphas, test.betas, tol)
+			var sum float64
+			for i := range p {
+				sum += p[i]
+			}
+			if n := len(p); math.Abs(sum-1) > float64(2*n)*tol {
+				t.Errorf("BetaWinner large sum error with small shapes. alphas: %v betas: %v tol: %g error: %g", test.alphas, test.betas, tol, 1-sum)
+			}
+		}
+	}
+
+	// Two uniforms: E[max] = 2/3
+	p, loss := BetaWinner([]float64{1, 1}, []float64{1, 1}, 1e-10)
+	for i := range p {
+		if math.Abs(p[i]-0.5) > 1e-10 || math.Abs(loss[i]-1.0/6) > 1e-10 {
+			t.Errorf("BetaWinner uniform mismatch. Got prob %v loss %v, want 0.5, %v", p[i], loss[i], 1.0/6)
+		}
+	}
+
+	p, loss = BetaWinner([]float64{3}, []float64{4}, 1e-8)
+	if p[0] != 1 || loss[0] != 0 {
+		t.Errorf("BetaWinner single arm mismatch. Got prob %v loss %v", p[0], loss[0])
+	}
+
+	for _, tol := range []float64{0, -1, math.NaN(), 1e-15} {
+		if p, loss := BetaWinner([]float64{1, 1}, []float64{1, 1}, tol); p != nil || loss != nil {
+			t.Errorf("BetaWinner accepted tol %v: got %v, %v", tol, p, loss)
+		}
+	}
+}
+
+func TestBetaWinnerSampled(t *testing.T) {
+	const tol = 5e-3
+	src := rand.NewSource(1)
+	for _, test := range []struct {
+		alphas, betas []float64
+		samples       int
+	}{
+		{[]float64{10, 12, 15}, []float64{90, 88, 85}, 200000},
+		{[]float64{1, 2, 3, 4}, []float64{4, 3, 2, 1}, 200000},
+		{[]float64{2.5, 2.5, 20}, []float64{2.5, 3.5, 25}, 200000},
+		// Shapes below 1 put the values near 0 and 1, so the loss has a
+		// large variance and needs more samples.
+		{[]float64{0.1, 0.2, 0.05}, []float64{0.1, 0.3, 0.5}, 2000000},
+	} {
+		samples := float64(test.samples)
+		n := len(test.alphas)
+		betas := make([]distuv.Beta, n)
+		for i := range betas {
+			betas[i] = distuv.Beta{Alpha: test.alphas[i], Beta: test.betas[i], Src: src}
+		}
+		wins := make([]float64, n)
+		loss := make([]float64, n)
+		x := make([]float64, n)
+		for s := 0; s < test.samples; s++ {
+			best := 0
+			for i := range x {
+				x[i] = betas[i].Rand()
+				if x[i] > x[best] {
+					best = i
+				}
+			}
+			wins[best]++
+			for i := range x {
+				loss[i] += x[best] - x[i]
+			}
+		}
+		for _, tol := range []float64{1e-3, 1e-8} {
+			p, l := BetaWinner(test.alphas, test.betas, tol)
+			var sum float64
+			for i := range p {
+				sum += p[i]
+				if math.Abs(p[i]-wins[i]/samples) > tol+5e-3 {
+					t.Errorf("BetaWinner sampling mismatch. alphas: %v betas: %v got: %v sampled: %v", test.alphas, test.betas, p[i], wins[i]/samples)
+				}
+				if math.Abs(l[i]-loss[i]/samples) > tol+1e-3 {
+					t.Errorf("BetaWinner loss sampling mismatch. alphas: %v betas: %v got: %v sampled: %v", test.alphas, test.betas, l[i], loss[i]/samples)
+				}
+			}
+			if math.Abs(sum-1) > float64(2*n)*tol {
+				t.Errorf("BetaWinner large sum error: %g tol: %g", 1-sum, tol)
+			}
+		}
+	}
+}
+
+func BenchmarkBetaWinner(b *testing.B) {
+	alphas := []float64{41, 52, 47}
+	betas := []float64{960, 950, 955}
+	for i := 0; i < b.N; i++ {
+		BetaWinner(alphas, betas, 1e-8)
+	}
+}
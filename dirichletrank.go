@@ -0,0 +1,156 @@
+package statext
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// dirichletRankAdaptiveQuadFunc is the function to integrate for row i of
+// the dirichlet rank probs. Entry r of result is the integrand for the
+// probability that output i has rank r, where rank 0 is the largest.
+// As in dirichletWinnerAdaptiveQuadFunc, y is mapped to
+// u = log(x) = mu + sigma*logit(y), and the pdf of output i is evaluated in
+// log space. logAlphas and norms are the precomputed log(alpha) and
+// gammaLogNorm(alpha) of each alpha.
+// buf is scratch space of length at least n+1.
+// Works in-place on result array.
+func dirichletRankAdaptiveQuadFunc(i int, mu, sigma, y float64, alphas, logAlphas, norms, result, buf []float64) {
+	if y == 0.0 || y == 1.0 {
+		for j := 0; j < len(result); j++ {
+			result[j] = 0.0
+		}
+		return
+	}
+	logY, log1mY := math.Log(y), math.Log1p(-y)
+	u := mu + sigma*(logY-log1mY)
+	logJac := math.Log(sigma) - logY - log1mY
+	logPdf, _, _ := dirichletRankGamma(alphas[i], logAlphas[i], norms[i], u)
+	pdf := math.Exp(logPdf + logJac)
+	if pdf == 0.0 {
+		for j := 0; j < len(result); j++ {
+			result[j] = 0.0
+		}
+		return
+	}
+	// poly holds the coefficients of product((cdfs[j] + survs[j]*t), j != i),
+	// the generating function for the number of other outputs greater than x.
+	// Every coefficient is in [0, 1], so any that underflow are negligible.
+	poly := buf[:len(alphas)]
+	poly[0] = 1.0
+	m := 1
+	for j, alpha := range alphas {
+		if j == i {
+			continue
+		}
+		_, cdf, surv := dirichletRankGamma(alpha, logAlphas[j], norms[j], u)
+		m++
+		polyMulLinear(poly[:m], cdf, surv)
+	}
+	for r := range result {
+		result[r] = pdf * poly[r]
+	}
+}
+
+// dirichletRankGamma returns log(pdf(x)*x), the cdf and the survival function
+// at x = exp(u) of the Gamma distribution with shape alpha. From
+// normalApproxAlpha, it uses the Wilson-Hilferty approximation of
+// dirichletWinnerNormalApprox, as the Gamma functions lose accuracy.
+func dirichletRankGamma(alpha, logAlpha, norm, u float64) (logPdf, cdf, surv float64) {
+	if alpha >= normalApproxAlpha {
+		c := math.Cbrt(alpha)
+		s := c / (3 * math.Sqrt(alpha))
+		t := math.Exp(u / 3)
+		z := (t - c*(1-1/(9*alpha))) / s
+		logPdf = -z*z/2 - 0.5*math.Log(2*math.Pi) + math.Log(t/(3*s))
+		return logPdf, 0.5 * math.Erfc(-z/math.Sqrt2), 0.5 * math.Erfc(z/math.Sqrt2)
+	}
+	d := u - logAlpha
+	logPdf = norm - alpha*(math.Expm1(d)-d)
+	// Take the smaller of the cdf and survival function directly, and the
+	// larger as its complement.
+	if c := logGammaIncReg(alpha, u); c < -math.Ln2 {
+		return logPdf, math.Exp(c), -math.Expm1(c)
+	}
+	sv := logGammaIncRegComp(alpha, u)
+	return logPdf, -math.Expm1(sv), math.Exp(sv)
+}
+
+// DirichletRank computes the probabilities that each output value of the
+// Dirichlet distribution will have each rank. Entry [i][r] of the result is
+// the probability that output i is the (r+1)-th largest, so [i][0] is the
+// probability that output i is the largest, as computed by DirichletWinner.
+// Each row and each column of the result sums to 1.
+// Uses the same adaptive quadrature integration technique as DirichletWinner,
+// with the number of other outputs larger than each point counted via the
+// elementary symmetric polynomials of the Gamma cdfs. Each row is integrated
+// separately, with the change of variables centred on the distribution of
+// its output, so that rows of very small and very large alphas are
+// resolved alike.
+// DirichletRank returns nil if tol or alphas are invalid, as for
+// DirichletWinnerE, other than for no alphas.
+func DirichletRank(alphas []float64, tol float64) [][]float64 {
+	if err := dirichletWinnerCheck(alphas, tol); err != nil && err != ErrNoAlphas {
+		return nil
+	}
+	n := len(alphas)
+	result := make([][]float64, n)
+	if n == 0 {
+		return result
+	}
+	flat := make([]float64, n*n)
+	for i := range result {
+		result[i] = flat[i*n : (i+1)*n]
+	}
+	if n == 1 {
+		result[0][0] = 1.0
+		return result
+	}
+	if n == 2 {
+		// The winner probs decide both ranks.
+		w := DirichletWinner(alphas, tol)
+		result[0][0], result[0][1] = w[0], w[1]
+		result[1][0], result[1][1] = w[1], w[0]
+		return result
+	}
+	logAlphas := make([]float64, n)
+	norms := make([]float64, n)
+	for j, alpha := range alphas {
+		logAlphas[j] = math.Log(alpha)
+		norms[j] = gammaLogNorm(alpha)
+	}
+	buf := make([]float64, n+1)
+	for i, alpha := range alphas {
+		// Centre on log(X) for X Gamma distributed with shape alpha. As
+		// y -> 0 the integrand behaves as y^(sigma*alpha-1), so sigma is kept
+		// at least 2/alpha for it to vanish smoothly.
+		mu, sigma := mathext.Digamma(alpha), math.Max(math.Sqrt(trigamma(alpha)), 2/alpha)
+		q := newAdaptiveQuad(func(y float64, dst []float64) {
+			dirichletRankAdaptiveQuadFunc(i, mu, sigma, y, alphas, logAlphas, norms, dst, buf)
+		}, n, tol)
+		q.integrate(n, 1, float64(2*n)*tol)
+		for r, v := range q.result {
+			result[i][r] = math.Min(v, 1)
+		}
+	}
+	return result
+}
+
+// DirichletTopK computes the probabilities that each output value of the
+// Dirichlet distribution will be among the k largest.
+// The result sums to min(k, len(alphas)).
+// DirichletTopK returns nil where DirichletRank does.
+func DirichletTopK(alphas []float64, k int, tol float64) []float64 {
+	rank := DirichletRank(alphas, tol)
+	if rank == nil {
+		return nil
+	}
+	result := make([]float64, len(alphas))
+	for i, row := range rank {
+		for r := 0; r < k && r < len(row); r++ {
+			result[i] += row[r]
+		}
+		result[i] = math.Min(result[i], 1)
+	}
+	return result
+}
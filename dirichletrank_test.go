@@ -0,0 +1,222 @@
+package statext
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestDirichletRankAdaptiveQuadFunc(t *testing.T) {
+	// Check the log-space integrand against directly multiplying out the
+	// generating function of the other outputs in linear space.
+	alphas := []float64{0.5, 3, 7.5, 20, 60}
+	n := len(alphas)
+	logAlphas := make([]float64, n)
+	norms := make([]float64, n)
+	for j, alpha := range alphas {
+		logAlphas[j] = math.Log(alpha)
+		norms[j] = gammaLogNorm(alpha)
+	}
+	result := make([]float64, n)
+	buf := make([]float64, n+1)
+	const mu, sigma = 2.0, 1.5
+	for _, y := range []float64{0.01, 0.2, 0.5, 0.7, 0.95} {
+		x := math.Exp(mu + sigma*math.Log(y/(1-y)))
+		jac := x * sigma / (y * (1 - y))
+		for i := 0; i < n; i++ {
+			dirichletRankAdaptiveQuadFunc(i, mu, sigma, y, alphas, logAlphas, norms, result, buf)
+			poly := []float64{1}
+			for j, alpha := range alphas {
+				if j == i {
+					continue
+				}
+				f, s := mathext.GammaIncReg(alpha, x), mathext.GammaIncRegComp(alpha, x)
+				next := make([]float64, len(poly)+1)
+				for m, c := range poly {
+					next[m] += c * f
+					next[m+1] += c * s
+				}
+				poly = next
+			}
+			// The coefficients sum to 1, so compare with absolute error
+			// relative to the pdf.
+			lg, _ := math.Lgamma(alphas[i])
+			pdf := math.Exp(math.Log(x)*(alphas[i]-1)-x-lg) * jac
+			for r := 0; r < n; r++ {
+				want := pdf * poly[r]
+				if got := result[r]; math.Abs(got-want) > 1e-12*pdf {
+					t.Errorf("Rank integrand mismatch at y=%v, i=%v, r=%v: got %g want %g", y, i, r, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDirichletRank(t *testing.T) {
+	for _, a := range [][]float64{
+		{1},
+		{2, 3},
+		{5.5, 10.5, 15.5},
+		{1, 2, 3, 4, 5, 6},
+		{1, 1.5, 20, 21},
+		{50.5, 100.5, 150.5},
+	} {
+		n := len(a)
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			r := DirichletRank(a, tol)
+			w := DirichletWinner(a, tol)
+			for i := 0; i < n; i++ {
+				var rowSum, colSum float64
+				for j := 0; j < n; j++ {
+					rowSum += r[i][j]
+					colSum += r[j][i]
+				}
+				if math.Abs(rowSum-1) > float64(2*n)*tol || math.Abs(colSum-1) > float64(2*n)*tol {
+					t.Errorf("Rank sum error. alphas: %v tol: %g row %v sum: %v column %v sum: %v", a, tol, i, rowSum, i, colSum)
+				}
+				if math.Abs(r[i][0]-w[i]) > 2*tol {
+					t.Errorf("Rank/winner mismatch. alphas: %v tol: %g rank: %v winner: %v", a, tol, r[i][0], w[i])
+				}
+			}
+		}
+	}
+}
+
+func TestDirichletRankExtreme(t *testing.T) {
+	// Tiny, huge and very unequal alphas, each with a row from the normal
+	// approximation.
+	for _, test := range []struct {
+		alphas []float64
+		want   [][]float64 // Known rank probs, if any
+	}{
+		{[]float64{0.001, 0.001, 0.001}, [][]float64{{1.0 / 3, 1.0 / 3, 1.0 / 3}, {1.0 / 3, 1.0 / 3, 1.0 / 3}, {1.0 / 3, 1.0 / 3, 1.0 / 3}}},
+		{[]float64{0.001, 1, 1000}, nil},
+		{[]float64{0.001, 1e7, 1.0005e7}, nil},
+		{[]float64{2e8, 1, 3}, [][]float64{{1, 0, 0}, {0, 0.125, 0.875}, {0, 0.875, 0.125}}},
+		{[]float64{1e9, 1.0001e9, 0.5, 3}, nil},
+	} {
+		a := test.alphas
+		n := len(a)
+		const tol = 1e-8
+		r := DirichletRank(a, tol)
+		w := DirichletWinner(a, tol)
+		for i := 0; i < n; i++ {
+			var rowSum, colSum float64
+			for j := 0; j < n; j++ {
+				if !(r[i][j] >= 0 && r[i][j] <= 1) {
+					t.Errorf("Rank probability out of [0, 1]. alphas: %v [%v][%v]: %v", a, i, j, r[i][j])
+				}
+				if test.want != nil && math.Abs(r[i][j]-test.want[i][j]) > 2*tol {
+					t.Errorf("Rank mismatch. alphas: %v [%v][%v] got: %v want: %v", a, i, j, r[i][j], test.want[i][j])
+				}
+				rowSum += r[i][j]
+				colSum += r[j][i]
+			}
+			if math.Abs(rowSum-1) > float64(2*n)*tol || math.Abs(colSum-1) > float64(2*n)*tol {
+				t.Errorf("Rank sum error. alphas: %v row %v sum: %v column %v sum: %v", a, i, rowSum, i, colSum)
+			}
+			if math.Abs(r[i][0]-w[i]) > float64(2*n)*tol {
+				t.Errorf("Rank/winner mismatch. alphas: %v rank: %v winner: %v", a, r[i][0], w[i])
+			}
+		}
+		// The smallest alpha is almost surely last.
+		if a[0] == 0.001 && a[1] >= 1e6 && math.Abs(r[0][n-1]-1) > 2*tol {
+			t.Errorf("Rank of tiny alpha. alphas: %v got: %v want: 1", a, r[0][n-1])
+		}
+	}
+}
+
+func TestDirichletRankInvalid(t *testing.T) {
+	for _, tol := range []float64{0, math.NaN()} {
+		for _, a := range [][]float64{{1, 2}, {1, 2, 3}} {
+			if r := DirichletRank(a, tol); r != nil {
+				t.Errorf("DirichletRank accepted tol %v: %v", tol, r)
+			}
+			if p := DirichletTopK(a, 1, tol); p != nil {
+				t.Errorf("DirichletTopK accepted tol %v: %v", tol, p)
+			}
+		}
+	}
+	for _, bad := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		for _, a := range [][]float64{{bad, 1}, {1, bad, 2}} {
+			if r := DirichletRank(a, 1e-8); r != nil {
+				t.Errorf("DirichletRank accepted alphas %v: %v", a, r)
+			}
+			if p := DirichletTopK(a, 1, 1e-8); p != nil {
+				t.Errorf("DirichletTopK accepted alphas %v: %v", a, p)
+			}
+		}
+	}
+	if r := DirichletRank(nil, 1e-8); r == nil || len(r) != 0 {
+		t.Errorf("DirichletRank with no alphas: got %#v, want an empty result", r)
+	}
+}
+
+func TestDirichletRankSampled(t *testing.T) {
+	const (
+		samples = 200000
+		tol     = 5e-3
+	)
+	src := rand.NewSource(1)
+	for _, a := range [][]float64{
+		{5.5, 10.5, 15.5},
+		{1, 2, 3, 4},
+	} {
+		n := len(a)
+		gammas := make([]distuv.Gamma, n)
+		for i, alpha := range a {
+			gammas[i] = distuv.Gamma{Alpha: alpha, Beta: 1, Src: src}
+		}
+		counts := make([][]float64, n)
+		for i := range counts {
+			counts[i] = make([]float64, n)
+		}
+		x := make([]float64, n)
+		idx := make([]int, n)
+		for s := 0; s < samples; s++ {
+			for i := range x {
+				x[i] = gammas[i].Rand()
+				idx[i] = i
+			}
+			sort.Slice(idx, func(i, j int) bool { return x[idx[i]] > x[idx[j]] })
+			for r, i := range idx {
+				counts[i][r]++
+			}
+		}
+		r := DirichletRank(a, 1e-8)
+		for i := range r {
+			for k := range r[i] {
+				if p := counts[i][k] / samples; math.Abs(p-r[i][k]) > tol {
+					t.Errorf("Rank sampling mismatch. alphas: %v [%v][%v] got: %v sampled: %v", a, i, k, r[i][k], p)
+				}
+			}
+		}
+	}
+}
+
+func TestDirichletTopK(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	const tol = 1e-8
+	w := DirichletWinner(a, tol)
+	for k := 0; k <= len(a)+1; k++ {
+		p := DirichletTopK(a, k, tol)
+		var sum float64
+		for i, v := range p {
+			sum += v
+			if k == 1 && math.Abs(v-w[i]) > 2*tol {
+				t.Errorf("Top 1 mismatch with winner: got %v want %v", v, w[i])
+			}
+			if k > 0 && i > 0 && v < p[i-1] {
+				t.Errorf("Top %v probabilities not increasing with alpha: %v", k, p)
+			}
+		}
+		want := math.Min(float64(k), float64(len(a)))
+		if math.Abs(sum-want) > float64(2*len(a)*len(a))*tol {
+			t.Errorf("Top %v sum mismatch: got %v want %v", k, sum, want)
+		}
+	}
+}
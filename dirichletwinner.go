@@ -1,121 +1,530 @@
 package statext
 
 import (
+	"errors"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
 	"math"
 )
 
-const (
-	maxDepth = 50 // Integrate to at most this depth (should never be reached)
-	minDepth = 2  // Integrate to at least this depth
-)
-
 // dirichletWinnerAdaptiveQuadFunc is the function to integrate for the
-// dirichlet winner probs.
+// dirichlet winner probs. The integration variable y is mapped to
+// u = log(x) = mu + sigma*logit(y), and the integrand is evaluated in log
+// space, so that neither very narrow spikes (large alphas) nor values of x
+// that underflow (tiny alphas) lose accuracy. logAlphas and norms are the
+// precomputed log(alpha) and gammaLogNorm(alpha) of each alpha.
+// logCDF is the log of the Gamma cdf, logGammaIncReg, or for the dirichlet
+// loser probs, the log of the Gamma survival function, logGammaIncRegComp.
 // Works in-place on result array.
-func dirichletWinnerAdaptiveQuadFunc(avgAlpha, y float64, alphas, result, lgammas []float64) {
+func dirichletWinnerAdaptiveQuadFunc(mu, sigma, y float64, alphas, logAlphas, norms, result []float64, logCDF func(a, u float64) float64) {
 	if y == 0.0 || y == 1.0 {
 		for j := 0; j < len(result); j++ {
 			result[j] = 0.0
 		}
 		return
 	}
-	x := avgAlpha * y / (1 - y)
-	cdfs := 1.0
-	var pdf, cdf float64
-	// Computes gamma(alphas[j]).pdf(x)*product(gamma(alphas[i]).cdf(x), i!=j) for each j
-	// Made faster by doing a single loop to compute each gamma(alphas[j]).pdf(x)/gamma(alphas[j]).cdf(x)
-	// And in the same loop computing product(gamma(alphas[j]).cdf(x))
-	// Then computing the final result by multiplying the pdf/cdf fractions by the cdfs product.
+	logY, log1mY := math.Log(y), math.Log1p(-y)
+	u := mu + sigma*(logY-log1mY)
+	logJac := math.Log(sigma) - logY - log1mY
+	var logCDFs float64
+	// Computes gamma(alphas[j]).pdf(x)*product(gamma(alphas[i]).cdf(x), i!=j)*dx/dy for each j
+	// Made faster by doing a single loop to compute each log(gamma(alphas[j]).pdf(x)*x/gamma(alphas[j]).cdf(x))
+	// And in the same loop computing log(product(gamma(alphas[j]).cdf(x)))
+	// Then computing the final result by adding the log of the cdfs product and the Jacobian.
+	// log(gamma(alpha).pdf(x)*x) is computed as norm - alpha*(e^d-1-d), with d = u-log(alpha),
+	// avoiding the cancellation between alpha*u, x and lgamma(alpha) for large alpha.
 	for j, alpha := range alphas {
-		pdf = math.Exp(math.Log(x)*(alpha-1) - x - lgammas[j])
-		cdf = mathext.GammaIncReg(alpha, x)
-		if cdf == 0.0 {
-			result[j] = 0.0
-			cdfs = 0.0
-			continue
+		c := logCDF(alpha, u)
+		if math.IsInf(c, -1) {
+			// The cdf underflows, so the whole product is negligible.
+			for k := 0; k < len(result); k++ {
+				result[k] = 0.0
+			}
+			return
 		}
-		result[j] = pdf / cdf
-		cdfs *= cdf
+		d := u - logAlphas[j]
+		result[j] = norms[j] - alpha*(math.Expm1(d)-d) - c
+		logCDFs += c
 	}
 	for j := 0; j < len(result); j++ {
-		result[j] *= avgAlpha * cdfs / ((1 - y) * (1 - y))
+		result[j] = math.Exp(result[j] + logCDFs + logJac)
 	}
 }
 
-// dirichletWinnerAdaptiveQuadRecursive implements an adaptive quadrature
-// integration over dirichletWinnerAdaptiveQuadFunc.
-// Works in-place on result array.
-func dirichletWinnerAdaptiveQuadRecursive(tol, avgAlpha, s, e float64, fs, fe, alphas, result, lgammas, ft []float64, depth, mnDepth int) {
-	n := len(result)
-	if depth == maxDepth {
-		for i := 0; i < n; i++ {
-			// Average the endpoints and return
-			result[i] += (fs[i] + fe[i]) * (e - s) / 2
-		}
-		//panic("Max depth")
-		return
+// gammaLogNorm returns alpha*log(alpha) - alpha - lgamma(alpha), the log of
+// gamma(alpha).pdf(x)*x at its mode x = alpha, using Stirling's series for
+// large alpha to avoid cancellation.
+func gammaLogNorm(alpha float64) float64 {
+	if alpha < 20 {
+		lg, _ := math.Lgamma(alpha)
+		return alpha*math.Log(alpha) - alpha - lg
 	}
-	var Q, Q2 float64
-	dirichletWinnerAdaptiveQuadFunc(avgAlpha, (s+e)/2, alphas, ft[:n], lgammas)
-	for i := 0; i < n; i++ {
-		Q = (fs[i] + fe[i]) * (e - s) / 2
-		Q2 = (fs[i] + 4*ft[i] + fe[i]) * (e - s) / 6
-		if math.Abs(Q-Q2) >= tol || depth < mnDepth {
-			// Error too large, divide
-			dirichletWinnerAdaptiveQuadRecursive(tol, avgAlpha, s, (s+e)/2, fs, ft[:n], alphas, result, lgammas, ft[n:], depth+1, mnDepth) // Left-half integration
-			dirichletWinnerAdaptiveQuadRecursive(tol, avgAlpha, (s+e)/2, e, ft[:n], fe, alphas, result, lgammas, ft[n:], depth+1, mnDepth) // Right-half integration
-			return
+	a2 := alpha * alpha
+	corr := (1 - (1-(1-(3.0/4)/a2)*(2.0/7)/a2)/(30*a2)) / (12 * alpha)
+	return 0.5*math.Log(alpha/(2*math.Pi)) - corr
+}
+
+// logGammaIncReg returns the log of the regularized lower incomplete Gamma
+// function P(a, x) at x = exp(u), remaining accurate where P or x underflow.
+func logGammaIncReg(a, u float64) float64 {
+	x := math.Exp(u)
+	// GammaIncReg loses accuracy for subnormal x.
+	if x > 1e-300 {
+		if p := mathext.GammaIncReg(a, x); p > 1e-300 {
+			return math.Log(p)
 		}
 	}
-	// Small enough error, return
-	for i := 0; i < n; i++ {
-		result[i] += (fs[i] + 4*ft[i] + fe[i]) * (e - s) / 6
+	// P(a, x) = x^a*e^-x/Γ(a+1) * Σ x^k/((a+1)...(a+k)), which converges
+	// quickly here since P only underflows for x much less than a.
+	sum, term := 1.0, 1.0
+	for k := 1.0; k < 1000 && term > sum*1e-17; k++ {
+		term *= x / (a + k)
+		sum += term
 	}
+	lg, _ := math.Lgamma(a + 1)
+	return a*u - x - lg + math.Log(sum)
+}
+
+// logGammaIncRegComp returns the log of the regularized upper incomplete
+// Gamma function Q(a, x) at x = exp(u), remaining accurate where Q or x
+// underflow.
+func logGammaIncRegComp(a, u float64) float64 {
+	x := math.Exp(u)
+	if x < 1 || x < a {
+		// Q = 1 - P, with P taken in log space since x may underflow.
+		return math.Log(-math.Expm1(logGammaIncReg(a, u)))
+	}
+	if q := mathext.GammaIncRegComp(a, x); q > 1e-300 {
+		return math.Log(q)
+	}
+	if math.IsInf(x, 1) {
+		return math.Inf(-1)
+	}
+	// Q(a, x) = x^a*e^-x/Γ(a) * 1/(x+1-a- 1(1-a)/(x+3-a- 2(2-a)/(x+5-a- ...))),
+	// evaluated with the modified Lentz method.
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1.0; i < 1000; i++ {
+		an := -i * (i - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < 1e-16 {
+			break
+		}
+	}
+	lg, _ := math.Lgamma(a)
+	return a*u - x - lg + math.Log(h)
+}
+
+// DirichletWinnerResult holds the result of DirichletWinnerDetailed,
+// along with diagnostics of the integration.
+type DirichletWinnerResult struct {
+	// Probs holds the probabilities that each output value of the
+	// Dirichlet distribution will be the largest.
+	Probs []float64
+	// Errors holds the estimated absolute error of each entry in Probs.
+	Errors []float64
+	// Evaluations is the number of integrand evaluations performed.
+	Evaluations int
+	// MaxDepth is the deepest subdivision reached by the integration.
+	MaxDepth int
+	// Converged reports whether the integration met the tolerance: no
+	// subinterval reached the maximum depth, and the probabilities sum to 1
+	// within the tolerance.
+	Converged bool
+	// NormalApprox reports whether the probabilities were computed with the
+	// normal approximation used for very large alphas, in which case Errors
+	// and the integration diagnostics are not populated.
+	NormalApprox bool
 }
 
 // DirichletWinner computes the probabilities that each
 // output value of the Dirichlet distribution will be the largest.
 // Uses an adaptive quadrature integration technique with the
+// Gamma representation of the Dirichlet distribution.
+//
+// DirichletWinner is DirichletWinnerE without the error: tolerances below
+// MinDirichletWinnerTol are raised to it, and no alphas give an empty
+// result. It returns nil for invalid alphas, and the best estimate if the
+// integration doesn't converge.
 func DirichletWinner(alphas []float64, tol float64) []float64 {
+	if len(alphas) == 0 {
+		return []float64{}
+	}
+	if !(tol >= MinDirichletWinnerTol) {
+		tol = MinDirichletWinnerTol
+	}
+	probs, _ := DirichletWinnerE(alphas, tol)
+	return probs
+}
+
+// MinDirichletWinnerTol is the smallest tolerance accepted by
+// DirichletWinnerE. Below it, rounding errors in the integrand dominate,
+// and the integration refines without converging.
+const MinDirichletWinnerTol = 1e-14
+
+// Errors returned by DirichletWinnerE.
+var (
+	ErrNoAlphas     = errors.New("dirichlet winner: no alphas")
+	ErrBadAlpha     = errors.New("dirichlet winner: alpha not positive and finite")
+	ErrBadTol       = errors.New("dirichlet winner: tolerance out of range")
+	ErrNotConverged = errors.New("dirichlet winner: integration did not converge")
+)
+
+// DirichletWinnerE computes the probabilities that each output value of the
+// Dirichlet distribution will be the largest, as DirichletWinner, checking
+// its inputs. It returns
+//  - ErrNoAlphas if alphas is empty,
+//  - ErrBadAlpha if any alpha is not positive and finite,
+//  - ErrBadTol if tol is NaN or less than MinDirichletWinnerTol,
+// along with a nil result. Otherwise, the result has the same length as
+// alphas and each probability is in [0, 1]. If the error is nil, the
+// probabilities sum to 1 within 2*len(alphas)*tol. If the integration
+// doesn't converge, the best estimate is returned with ErrNotConverged.
+func DirichletWinnerE(alphas []float64, tol float64) ([]float64, error) {
+	if err := dirichletWinnerCheck(alphas, tol); err != nil {
+		return nil, err
+	}
+	res := DirichletWinnerDetailed(alphas, tol)
+	for i, v := range res.Probs {
+		res.Probs[i] = math.Min(v, 1)
+	}
+	if !res.Converged {
+		return res.Probs, ErrNotConverged
+	}
+	return res.Probs, nil
+}
+
+// dirichletWinnerCheck returns the error DirichletWinnerE reports for tol
+// and alphas, or nil if they are valid.
+func dirichletWinnerCheck(alphas []float64, tol float64) error {
+	if !(tol >= MinDirichletWinnerTol) {
+		return ErrBadTol
+	}
+	if len(alphas) == 0 {
+		return ErrNoAlphas
+	}
+	for _, alpha := range alphas {
+		if !(alpha > 0) || math.IsInf(alpha, 1) {
+			return ErrBadAlpha
+		}
+	}
+	return nil
+}
+
+// DirichletWinnerDetailed computes the probabilities that each output value
+// of the Dirichlet distribution will be the largest, as DirichletWinner,
+// and reports error estimates and diagnostics of the integration.
+// If tol or alphas are invalid, as for DirichletWinnerE, the result is empty
+// and not converged.
+func DirichletWinnerDetailed(alphas []float64, tol float64) DirichletWinnerResult {
+	return DirichletWinnerMethod(alphas, tol, AdaptiveSimpson)
+}
+
+// DirichletWinnerMethod computes the probabilities that each output value of
+// the Dirichlet distribution will be the largest, as DirichletWinnerDetailed,
+// integrating with the given method. For GaussKronrod, MaxDepth is the
+// deepest bisection, and for TanhSinh, the finest level reached.
+// If tol or alphas are invalid, as for DirichletWinnerE, the result is empty
+// and not converged.
+func DirichletWinnerMethod(alphas []float64, tol float64, method QuadMethod) DirichletWinnerResult {
+	if err := dirichletWinnerCheck(alphas, tol); err != nil && err != ErrNoAlphas {
+		return DirichletWinnerResult{}
+	}
 	n := len(alphas)
-	result := make([]float64, n)
+	res := DirichletWinnerResult{
+		Probs:     make([]float64, n),
+		Errors:    make([]float64, n),
+		Converged: true,
+	}
+	if n == 0 {
+		return res
+	}
 	if n == 1 {
-		result[0] = 1.0
-		return result
+		res.Probs[0] = 1.0
+		return res
 	}
-	if n == 2 {
+	if floats.Max(alphas) >= normalApproxAlpha {
+		res.Probs = dirichletWinnerNormalApprox(alphas, tol, false)
+		res.NormalApprox = true
+		res.Converged = math.Abs(floats.Sum(res.Probs)-1) <= float64(2*n)*tol
+		return res
+	}
+	if n == 2 && math.Min(alphas[0], alphas[1]) <= maxRegIncBetaAlpha {
 		b := mathext.RegIncBeta(alphas[0], alphas[1], 0.5)
-		result[0] = 1.0 - b
-		result[1] = b
-		return result
+		res.Probs[0] = 1.0 - b
+		res.Probs[1] = b
+		return res
 	}
-	lgammas := make([]float64, n)
-	// Pre-computed average of alpha values
-	// Used to improve integration accuracy by focusing around the critical points
-	var avgAlpha float64
-	for j, alpha := range alphas {
-		lgammas[j], _ = math.Lgamma(alpha)
-		avgAlpha += alpha
-	}
-	avgAlpha /= float64(n)
-	fs := make([]float64, n)                                            // function result at start point (0.0)
-	fe := make([]float64, n)                                            // function result at end point (1.0)
-	ft := make([]float64, maxDepth*n)                                   // Buffer space to use as fs/fe at lower depths
-	dirichletWinnerAdaptiveQuadFunc(avgAlpha, 0.0, alphas, fs, lgammas) // Compute start point function result
-	dirichletWinnerAdaptiveQuadFunc(avgAlpha, 1.0, alphas, fe, lgammas) // Compute end point function result
-	for md := minDepth; md < maxDepth; md++ {
-		dirichletWinnerAdaptiveQuadRecursive(tol, avgAlpha, 0.0, 1.0, fs, fe, alphas, result, lgammas, ft, 0, md)
-		var sr float64
-		for _, v := range result {
-			sr += v
+	var qr quadResult
+	switch method {
+	case AdaptiveSimpson:
+		q, sumMet := dirichletWinnerQuad(alphas, tol, false)
+		res.Probs = q.result
+		res.Errors = q.errs
+		res.Evaluations = q.evals
+		res.MaxDepth = q.depth
+		res.Converged = sumMet && !q.capped
+		return res
+	case GaussKronrod:
+		qr = gaussKronrodQuad(dirichletWinnerIntegrand(alphas, false), n, tol)
+	case TanhSinh:
+		qr = tanhSinhQuad(dirichletWinnerIntegrand(alphas, false), n, tol)
+	default:
+		panic("dirichlet winner: unknown quadrature method")
+	}
+	res.Probs = qr.result
+	res.Errors = qr.errs
+	res.Evaluations = qr.evals
+	res.MaxDepth = qr.depth
+	res.Converged = qr.converged && math.Abs(floats.Sum(qr.result)-1) <= float64(2*n)*tol
+	return res
+}
+
+// DirichletWinnerLoss computes the probabilities that each output value of
+// the Dirichlet distribution will be the largest, as DirichletWinner.
+// It also computes the expected loss (or "value remaining") of each,
+// E[max_j X_j - X_i], the expected shortfall from choosing i over the
+// largest.
+// Uses the same adaptive quadrature integration technique as DirichletWinner,
+// with an additional x-weighted integrand: with X = G/sum(G) for independent
+// G_j Gamma distributed with shape alphas[j], and sum(G) independent of X,
+// E[max_j X_j] = E[max_j G_j]/sum(alphas).
+// DirichletWinnerLoss returns nil slices if tol is NaN or less than
+// MinDirichletWinnerTol.
+func DirichletWinnerLoss(alphas []float64, tol float64) (probs, loss []float64) {
+	if !(tol >= MinDirichletWinnerTol) {
+		return nil, nil
+	}
+	n := len(alphas)
+	switch n {
+	case 0:
+		return []float64{}, []float64{}
+	case 1:
+		return []float64{1.0}, []float64{0.0}
+	}
+	logAlphas := make([]float64, n)
+	norms := make([]float64, n)
+	mu, sigma := dirichletWinnerPrepare(alphas, logAlphas, norms, false)
+	sum := floats.Sum(alphas)
+	q := newAdaptiveQuad(func(y float64, dst []float64) {
+		dirichletWinnerAdaptiveQuadFunc(mu, sigma, y, alphas, logAlphas, norms, dst[:n], logGammaIncReg)
+		// x*gamma(alphas[j]).pdf(x)*product(gamma(alphas[i]).cdf(x), i!=j), summed over j,
+		// integrates to E[max_j G_j].
+		dst[n] = floats.Sum(dst[:n])
+		if dst[n] != 0.0 {
+			dst[n] *= math.Exp(mu+sigma*(math.Log(y)-math.Log1p(-y))) / sum
 		}
-		if math.Abs(sr-1) <= float64(2*n)*tol {
-			break
+	}, n+1, tol)
+	q.integrate(n, 1, float64(2*n)*tol)
+	probs = q.result[:n:n]
+	loss = make([]float64, n)
+	for i, alpha := range alphas {
+		// E[max_j X_j] - E[X_i]
+		loss[i] = math.Max(q.result[n]-alpha/sum, 0)
+	}
+	return probs, loss
+}
+
+// DirichletLoser computes the probabilities that each
+// output value of the Dirichlet distribution will be the smallest.
+// Uses the same adaptive quadrature integration technique as DirichletWinner.
+// DirichletLoser returns nil if tol or alphas are invalid, as for
+// DirichletWinnerE, other than for no alphas.
+func DirichletLoser(alphas []float64, tol float64) []float64 {
+	if err := dirichletWinnerCheck(alphas, tol); err != nil && err != ErrNoAlphas {
+		return nil
+	}
+	n := len(alphas)
+	if n <= 2 {
+		// The smallest of two is the largest with the order reversed.
+		result := DirichletWinner(alphas, tol)
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
 		}
-		for i := range result {
-			result[i] = 0
+		return result
+	}
+	if floats.Min(alphas) >= normalApproxAlpha {
+		return dirichletWinnerNormalApprox(alphas, tol, true)
+	}
+	q, _ := dirichletWinnerQuad(alphas, tol, true)
+	return q.result
+}
+
+const (
+	// maxRegIncBetaAlpha is the largest alpha for which the winner probs of
+	// two outputs are computed in closed form with mathext.RegIncBeta, which
+	// loses accuracy for larger parameters.
+	maxRegIncBetaAlpha = 1e4
+	// normalApproxAlpha is the alpha from which the winner probs are
+	// computed with dirichletWinnerNormalApprox. Beyond this, the error of
+	// the approximation, around 1/(400*alpha), is smaller than the rounding
+	// error of the integrand, which grows as sqrt(alpha).
+	normalApproxAlpha = 1e8
+)
+
+// dirichletWinnerNormalApprox computes the winner probs, or if loser is set,
+// the loser probs, with the Wilson-Hilferty approximation: for X Gamma
+// distributed with shape alpha, X^(1/3) is approximately normal with mean
+// alpha^(1/3)*(1-1/(9*alpha)) and standard deviation alpha^(1/3)/(3*sqrt(alpha)).
+// The cube root is increasing, so the largest of the cube roots belongs to
+// the largest output.
+func dirichletWinnerNormalApprox(alphas []float64, tol float64, loser bool) []float64 {
+	dists := make([]ProbCDFer, len(alphas))
+	for i, alpha := range alphas {
+		c := math.Cbrt(alpha)
+		mu := c * (1 - 1/(9*alpha))
+		if loser {
+			mu = -mu
 		}
+		dists[i] = distuv.Normal{Mu: mu, Sigma: c / (3 * math.Sqrt(alpha))}
+	}
+	return WinnerProbabilities(dists, math.Inf(-1), math.Inf(1), tol)
+}
+
+// dirichletWinnerQuad integrates dirichletWinnerAdaptiveQuadFunc for the
+// winner probs, or if loser is set, the loser probs, reporting whether the
+// results summed to 1 within the tolerance.
+func dirichletWinnerQuad(alphas []float64, tol float64, loser bool) (*adaptiveQuad, bool) {
+	n := len(alphas)
+	q := newAdaptiveQuad(dirichletWinnerIntegrand(alphas, loser), n, tol)
+	sumMet := q.integrate(n, 1, float64(2*n)*tol)
+	return q, sumMet
+}
+
+// dirichletWinnerIntegrand returns dirichletWinnerAdaptiveQuadFunc for the
+// winner probs, or if loser is set, the loser probs, as a function of y.
+func dirichletWinnerIntegrand(alphas []float64, loser bool) func(y float64, dst []float64) {
+	n := len(alphas)
+	logAlphas := make([]float64, n)
+	norms := make([]float64, n)
+	mu, sigma := dirichletWinnerPrepare(alphas, logAlphas, norms, loser)
+	logCDF := logGammaIncReg
+	if loser {
+		logCDF = logGammaIncRegComp
+	}
+	return func(y float64, dst []float64) {
+		dirichletWinnerAdaptiveQuadFunc(mu, sigma, y, alphas, logAlphas, norms, dst, logCDF)
+	}
+}
+
+// dirichletWinnerPrepare precomputes the log(alpha) and gammaLogNorm(alpha)
+// of each alpha for dirichletWinnerAdaptiveQuadFunc, and returns the centre
+// mu and scale sigma of its change of variables.
+// For the winner probs, the integrand is concentrated where the largest
+// outputs compete, so the change of variables is centred on the mean and
+// standard deviation of log(X) for X Gamma distributed with the largest
+// alpha. For the loser probs, it is centred on the smallest alpha.
+// As y -> 0 the integrand behaves as y^(sigma*k-1), where k is the sum of
+// the alphas for the winner probs, and the smallest alpha for the loser
+// probs, so sigma is kept at least 2/k for the integrand to vanish smoothly.
+func dirichletWinnerPrepare(alphas, logAlphas, norms []float64, loser bool) (mu, sigma float64) {
+	max, min, sum := alphas[0], alphas[0], 0.0
+	for j, alpha := range alphas {
+		logAlphas[j] = math.Log(alpha)
+		norms[j] = gammaLogNorm(alpha)
+		max = math.Max(max, alpha)
+		min = math.Min(min, alpha)
+		sum += alpha
+	}
+	centre, k := max, sum
+	if loser {
+		centre, k = min, min
+	}
+	mu, sigma = mathext.Digamma(centre), math.Sqrt(trigamma(centre))
+	return mu, math.Max(sigma, 2/k)
+}
+
+// DirichletWinnerWorkspace holds the buffers used to compute the Dirichlet
+// winner probabilities, so that they can be reused across calls without
+// allocating. The zero value is ready to use.
+// A DirichletWinnerWorkspace must not be used concurrently.
+type DirichletWinnerWorkspace struct {
+	// Parallel is the number of goroutines across which to split the
+	// integration domain, rounded up to a power of two. Values less than
+	// 2 integrate serially. Splitting can help for large numbers of alphas,
+	// where each evaluation of the integrand is expensive, but only with
+	// spare CPUs: on a single CPU it adds overhead.
+	Parallel int
+
+	alphas    []float64
+	logAlphas []float64
+	norms     []float64
+	mu, sigma float64
+	quad      *adaptiveQuad
+	chunks    int
+}
+
+// eval is the integrand for the workspace's current alphas.
+func (w *DirichletWinnerWorkspace) eval(y float64, dst []float64) {
+	dirichletWinnerAdaptiveQuadFunc(w.mu, w.sigma, y, w.alphas, w.logAlphas, w.norms, dst, logGammaIncReg)
+}
+
+// DirichletWinner computes the probabilities that each output value of the
+// Dirichlet distribution will be the largest, as the DirichletWinner function,
+// storing the result in dst. If dst is nil, a new slice is allocated.
+// As for the DirichletWinner function, tolerances below MinDirichletWinnerTol
+// are raised to it, and it returns nil, leaving dst unchanged, for invalid
+// alphas. DirichletWinner panics if dst is not nil and len(dst) != len(alphas).
+func (w *DirichletWinnerWorkspace) DirichletWinner(dst, alphas []float64, tol float64) []float64 {
+	if !(tol >= MinDirichletWinnerTol) {
+		tol = MinDirichletWinnerTol
+	}
+	if err := dirichletWinnerCheck(alphas, tol); err != nil && err != ErrNoAlphas {
+		return nil
+	}
+	n := len(alphas)
+	if dst == nil {
+		dst = make([]float64, n)
+	} else if len(dst) != n {
+		panic("dirichlet winner: slice length mismatch")
+	}
+	switch n {
+	case 0:
+		return dst
+	case 1:
+		dst[0] = 1.0
+		return dst
+	}
+	if floats.Max(alphas) >= normalApproxAlpha {
+		copy(dst, dirichletWinnerNormalApprox(alphas, tol, false))
+		return dst
+	}
+	if n == 2 && math.Min(alphas[0], alphas[1]) <= maxRegIncBetaAlpha {
+		b := mathext.RegIncBeta(alphas[0], alphas[1], 0.5)
+		dst[0] = 1.0 - b
+		dst[1] = b
+		return dst
+	}
+	w.alphas = alphas
+	w.logAlphas = resize(w.logAlphas, n)
+	w.norms = resize(w.norms, n)
+	w.mu, w.sigma = dirichletWinnerPrepare(alphas, w.logAlphas, w.norms, false)
+	if w.quad == nil {
+		w.quad = newAdaptiveQuad(w.eval, n, tol)
+	} else {
+		w.quad.reset(n, tol)
+	}
+	if w.chunks != w.Parallel {
+		w.quad.split(w.Parallel)
+		w.chunks = w.Parallel
 	}
-	return result
+	w.quad.integrate(n, 1, float64(2*n)*tol)
+	copy(dst, w.quad.result)
+	w.alphas = nil
+	return dst
 }
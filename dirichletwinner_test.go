@@ -1,9 +1,15 @@
 package statext
 
 import (
+	"fmt"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distmv"
 	"math"
 	"math/rand"
 	"testing"
+
+	xrand "golang.org/x/exp/rand"
 )
 
 func runDirichletWinnerTestCase(a, rt []float64, t *testing.T) {
@@ -37,9 +43,13 @@ func runDirichletWinnerRandTestCase(a []float64, t *testing.T, full bool) {
 }
 
 func TestDirichletWinner(t *testing.T) {
+	// The last probability of the second case was 0.9992427806931501 before
+	// the integrand moved to log space, leaving the case summing to 1+1.5e-13.
+	// The other two probabilities are unchanged, and the last is now
+	// 1 minus their sum, to within 2e-16.
 	testCases := [][2][]float64{
 		{{5.5, 10.5, 15.5}, {0.006730827936742794, 0.15691248315301745, 0.83635668891024}},
-		{{50.5, 100.5, 150.5}, {1.2913384498578148e-13, 0.0007572193068734463, 0.9992427806931501}},
+		{{50.5, 100.5, 150.5}, {1.2913384498578148e-13, 0.0007572193068734463, 0.9992427806929976}},
 	}
 	for _, testCase := range testCases {
 		runDirichletWinnerTestCase(testCase[0], testCase[1], t)
@@ -81,6 +91,414 @@ func TestDirichletWinner(t *testing.T) {
 	}
 }
 
+func TestDirichletWinnerDetailed(t *testing.T) {
+	testCases := [][2][]float64{
+		{{5.5, 10.5, 15.5}, {0.006730827936742794, 0.15691248315301745, 0.83635668891024}},
+		{{50.5, 100.5, 150.5}, {1.2913384498578148e-13, 0.0007572193068734463, 0.9992427806929976}},
+	}
+	for _, testCase := range testCases {
+		a, rt := testCase[0], testCase[1]
+		for _, tol := range []float64{1e-3, 1e-8, 1e-13} {
+			r := DirichletWinnerDetailed(a, tol)
+			if !r.Converged {
+				t.Errorf("Dirichlet not converged. alphas: %v tol: %g", a, tol)
+			}
+			if r.Evaluations < 3 || r.MaxDepth < minDepth || r.MaxDepth >= maxDepth {
+				t.Errorf("Dirichlet bad diagnostics. alphas: %v tol: %g evaluations: %v max depth: %v", a, tol, r.Evaluations, r.MaxDepth)
+			}
+			p := DirichletWinner(a, tol)
+			for i := range a {
+				if r.Probs[i] != p[i] {
+					t.Errorf("Dirichlet detailed mismatch: %g target: %g tol: %g", r.Probs[i], p[i], tol)
+				}
+				if math.Abs(rt[i]-r.Probs[i]) > r.Errors[i]+1e-14 {
+					t.Errorf("Dirichlet error underestimated: %g actual error: %g tol: %g", r.Errors[i], rt[i]-r.Probs[i], tol)
+				}
+			}
+		}
+	}
+	r := DirichletWinnerDetailed([]float64{1, 2}, 1e-8)
+	if !r.Converged || r.Evaluations != 0 || r.Errors[0] != 0 || r.Errors[1] != 0 {
+		t.Errorf("Dirichlet two-component diagnostics mismatch: %+v", r)
+	}
+}
+
+func TestDirichletWinnerMethod(t *testing.T) {
+	testCases := [][2][]float64{
+		{{5.5, 10.5, 15.5}, {0.006730827936742794, 0.15691248315301745, 0.83635668891024}},
+		{{50.5, 100.5, 150.5}, {1.2913384498578148e-13, 0.0007572193068734463, 0.9992427806929976}},
+	}
+	for _, method := range []QuadMethod{AdaptiveSimpson, GaussKronrod, TanhSinh} {
+		for _, testCase := range testCases {
+			a, rt := testCase[0], testCase[1]
+			for _, tol := range []float64{1e-3, 1e-8, 1e-13} {
+				r := DirichletWinnerMethod(a, tol, method)
+				if !r.Converged || r.Evaluations == 0 {
+					t.Errorf("Dirichlet method not converged. method: %v alphas: %v tol: %g", method, a, tol)
+				}
+				for i := range a {
+					if math.Abs(rt[i]-r.Probs[i]) > tol {
+						t.Errorf("Dirichlet method large error: %g method: %v target: %g result: %g tol: %g", rt[i]-r.Probs[i], method, rt[i], r.Probs[i], tol)
+					}
+				}
+			}
+		}
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 50; i++ {
+			a := make([]float64, rnd.Intn(8)+3)
+			for j := range a {
+				a[j] = math.Pow(10, rnd.Float64()*6-2)
+			}
+			want := DirichletWinner(a, 1e-11)
+			r := DirichletWinnerMethod(a, 1e-8, method)
+			if !r.Converged {
+				t.Errorf("Dirichlet method not converged. method: %v alphas: %v", method, a)
+			}
+			for j := range a {
+				if math.Abs(want[j]-r.Probs[j]) > 2e-8 {
+					t.Errorf("Dirichlet method mismatch. method: %v alphas: %v got: %v want: %v", method, a, r.Probs[j], want[j])
+				}
+			}
+		}
+	}
+}
+
+func TestDirichletWinnerLoss(t *testing.T) {
+	// With two outputs, X_1 is Beta(a, b) distributed, and
+	// E[max(X_1, X_2)] = a/(a+b)*(1-I_0.5(a+1, b)) + b/(a+b)*(1-I_0.5(b+1, a)).
+	for _, a := range [][]float64{{1, 1}, {2, 3}, {0.5, 4}, {40, 41}, {1e-3, 2e-3}} {
+		sum := a[0] + a[1]
+		emax := a[0]/sum*(1-mathext.RegIncBeta(a[0]+1, a[1], 0.5)) + a[1]/sum*(1-mathext.RegIncBeta(a[1]+1, a[0], 0.5))
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			probs, loss := DirichletWinnerLoss(a, tol)
+			want := DirichletWinner(a, tol)
+			for i := range a {
+				if math.Abs(probs[i]-want[i]) > 4*tol {
+					t.Errorf("Dirichlet loss probability mismatch. alphas: %v tol: %g got: %v want: %v", a, tol, probs[i], want[i])
+				}
+				if l := emax - a[i]/sum; math.Abs(loss[i]-l) > 4*tol {
+					t.Errorf("Dirichlet loss mismatch. alphas: %v tol: %g got: %v want: %v", a, tol, loss[i], l)
+				}
+			}
+		}
+	}
+	// Against sampled losses, for more outputs.
+	for _, a := range [][]float64{{5.5, 10.5, 15.5}, {1, 2, 3, 4, 5, 6}, {0.3, 0.5, 0.7}} {
+		probs, loss := DirichletWinnerLoss(a, 1e-10)
+		want := DirichletWinner(a, 1e-10)
+		const samples = 200000
+		sampled := make([]float64, len(a))
+		dir := distmv.NewDirichlet(a, xrand.NewSource(1))
+		x := make([]float64, len(a))
+		for k := 0; k < samples; k++ {
+			dir.Rand(x)
+			max := floats.Max(x)
+			for j := range x {
+				sampled[j] += (max - x[j]) / samples
+			}
+		}
+		for i := range a {
+			if math.Abs(probs[i]-want[i]) > 1e-9 {
+				t.Errorf("Dirichlet loss probability mismatch. alphas: %v got: %v want: %v", a, probs[i], want[i])
+			}
+			if loss[i] < 0 || math.Abs(loss[i]-sampled[i]) > 3e-3 {
+				t.Errorf("Dirichlet loss sampled mismatch. alphas: %v got: %v sampled: %v", a, loss[i], sampled[i])
+			}
+		}
+	}
+	if probs, loss := DirichletWinnerLoss([]float64{3}, 1e-8); probs[0] != 1 || loss[0] != 0 {
+		t.Errorf("Dirichlet loss single output mismatch. probs: %v loss: %v", probs, loss)
+	}
+	for _, tol := range []float64{0, -1, math.NaN(), 1e-15} {
+		if probs, loss := DirichletWinnerLoss([]float64{1, 2}, tol); probs != nil || loss != nil {
+			t.Errorf("DirichletWinnerLoss accepted tol %v: got %v, %v", tol, probs, loss)
+		}
+	}
+}
+
+func TestDirichletWinnerE(t *testing.T) {
+	for _, test := range []struct {
+		alphas []float64
+		tol    float64
+		err    error
+	}{
+		{nil, 1e-8, ErrNoAlphas},
+		{[]float64{}, 1e-8, ErrNoAlphas},
+		{[]float64{1, 0, 2}, 1e-8, ErrBadAlpha},
+		{[]float64{1, -1, 2}, 1e-8, ErrBadAlpha},
+		{[]float64{1, math.NaN(), 2}, 1e-8, ErrBadAlpha},
+		{[]float64{1, 2, math.Inf(1)}, 1e-8, ErrBadAlpha},
+		{[]float64{1, 2, 3}, 0, ErrBadTol},
+		{[]float64{1, 2, 3}, -1e-8, ErrBadTol},
+		{[]float64{1, 2, 3}, math.NaN(), ErrBadTol},
+		{[]float64{1, 2, 3}, 1e-15, ErrBadTol},
+		{[]float64{1}, 1e-8, nil},
+		{[]float64{1, 2}, 1e-8, nil},
+		{[]float64{5.5, 10.5, 15.5}, 1e-8, nil},
+		{[]float64{5.5, 10.5, 15.5}, MinDirichletWinnerTol, nil},
+		{[]float64{1e-3, 1, 1e3, 1e7}, 1e-11, nil},
+	} {
+		p, err := DirichletWinnerE(test.alphas, test.tol)
+		if err != test.err {
+			t.Errorf("DirichletWinnerE error mismatch. alphas: %v tol: %g got: %v want: %v", test.alphas, test.tol, err, test.err)
+		}
+		if err != nil {
+			if p != nil {
+				t.Errorf("DirichletWinnerE returned a result for invalid input. alphas: %v tol: %g", test.alphas, test.tol)
+			}
+			// DirichletWinner keeps its behaviour for no alphas and small
+			// tolerances.
+			w := DirichletWinner(test.alphas, test.tol)
+			switch err {
+			case ErrNoAlphas:
+				if w == nil || len(w) != 0 {
+					t.Errorf("DirichletWinner with no alphas: got %#v, want an empty slice", w)
+				}
+			case ErrBadAlpha:
+				if w != nil {
+					t.Errorf("DirichletWinner returned a result for invalid alphas: %v", test.alphas)
+				}
+			case ErrBadTol:
+				if len(w) != len(test.alphas) || math.Abs(floats.Sum(w)-1) > float64(2*len(w))*MinDirichletWinnerTol {
+					t.Errorf("DirichletWinner with tol %g: got %v", test.tol, w)
+				}
+			}
+			continue
+		}
+		if len(p) != len(test.alphas) {
+			t.Fatalf("DirichletWinnerE length mismatch. alphas: %v got: %v", test.alphas, p)
+		}
+		var sum float64
+		for _, v := range p {
+			if v < 0 || v > 1 {
+				t.Errorf("DirichletWinnerE probability out of range. alphas: %v got: %v", test.alphas, v)
+			}
+			sum += v
+		}
+		if math.Abs(sum-1) > float64(2*len(p))*test.tol {
+			t.Errorf("DirichletWinnerE large sum error: %g alphas: %v tol: %g", 1-sum, test.alphas, test.tol)
+		}
+	}
+	if r := DirichletWinnerDetailed(nil, 1e-8); len(r.Probs) != 0 || !r.Converged {
+		t.Errorf("Dirichlet empty detailed mismatch: %+v", r)
+	}
+}
+
+func TestDirichletWinnerInvalid(t *testing.T) {
+	a := []float64{5.5, 10.5, 15.5}
+	for _, tol := range []float64{0, math.NaN()} {
+		for _, method := range []QuadMethod{AdaptiveSimpson, GaussKronrod, TanhSinh} {
+			if r := DirichletWinnerMethod(a, tol, method); r.Probs != nil || r.Converged {
+				t.Errorf("DirichletWinnerMethod accepted tol %v with method %v: %+v", tol, method, r)
+			}
+		}
+		if r := DirichletWinnerDetailed(a, tol); r.Probs != nil || r.Converged {
+			t.Errorf("DirichletWinnerDetailed accepted tol %v: %+v", tol, r)
+		}
+		if r := DirichletWinnerDetailed(nil, tol); r.Probs != nil || r.Converged {
+			t.Errorf("DirichletWinnerDetailed accepted tol %v with no alphas: %+v", tol, r)
+		}
+		for _, a := range [][]float64{a, a[:2]} {
+			if p := DirichletLoser(a, tol); p != nil {
+				t.Errorf("DirichletLoser accepted tol %v: %v", tol, p)
+			}
+		}
+		// The workspace raises the tolerance, as DirichletWinner does.
+		var w DirichletWinnerWorkspace
+		got := w.DirichletWinner(nil, a, tol)
+		want := DirichletWinner(a, tol)
+		if !floats.Equal(got, want) {
+			t.Errorf("Dirichlet workspace with tol %v: got %v want %v", tol, got, want)
+		}
+	}
+	// Invalid alphas, as for DirichletWinnerE.
+	for _, a := range [][]float64{{1, 0, 2}, {1, math.NaN(), 2}, {1, math.Inf(1), 2}} {
+		if r := DirichletWinnerDetailed(a, 1e-8); r.Probs != nil || r.Converged {
+			t.Errorf("DirichletWinnerDetailed accepted alphas %v: %+v", a, r)
+		}
+	}
+	var w DirichletWinnerWorkspace
+	for _, a := range [][]float64{{0, 1}, {1, -1}, {math.NaN(), 1}, {1, math.Inf(1)}, {1, 0, 2}, {1, math.NaN(), 2}, {1, math.Inf(1), 2}} {
+		if p := DirichletLoser(a, 1e-8); p != nil {
+			t.Errorf("DirichletLoser accepted alphas %v: %v", a, p)
+		}
+		if p := DirichletWinner(a, 1e-8); p != nil {
+			t.Errorf("DirichletWinner accepted alphas %v: %v", a, p)
+		}
+		dst := []float64{-1, -1, -1}[:len(a)]
+		if p := w.DirichletWinner(dst, a, 1e-8); p != nil || dst[0] != -1 {
+			t.Errorf("Dirichlet workspace accepted alphas %v: %v", a, p)
+		}
+	}
+	// The workspace, like DirichletWinner, gives an empty result for no alphas.
+	if p := w.DirichletWinner(nil, nil, 1e-8); p == nil || len(p) != 0 {
+		t.Errorf("Dirichlet workspace with no alphas: got %#v, want an empty slice", p)
+	}
+	if p := DirichletLoser(nil, 1e-8); p == nil || len(p) != 0 {
+		t.Errorf("DirichletLoser with no alphas: got %#v, want an empty slice", p)
+	}
+}
+
+func TestDirichletWinnerExtreme(t *testing.T) {
+	// Symmetric alphas, including the normal approximation for very large alphas.
+	for _, a := range [][]float64{
+		{1e-3, 1e-3, 1e-3},
+		{0.01, 0.01, 0.01, 0.01, 0.01},
+		{1e7, 1e7, 1e7, 1e7},
+		{1e9, 1e9, 1e9},
+	} {
+		for _, tol := range []float64{1e-8, 1e-10} {
+			r := DirichletWinnerDetailed(a, tol)
+			if !r.Converged || r.NormalApprox != (a[0] >= normalApproxAlpha) {
+				t.Errorf("Dirichlet extreme diagnostics mismatch. alphas: %v tol: %g converged: %v normal approx: %v", a, tol, r.Converged, r.NormalApprox)
+			}
+			for _, v := range r.Probs {
+				if math.Abs(v-1/float64(len(a))) > tol {
+					t.Errorf("Dirichlet extreme symmetric mismatch. alphas: %v tol: %g got: %v", a, tol, v)
+				}
+			}
+			for _, v := range DirichletLoser(a, tol) {
+				if math.Abs(v-1/float64(len(a))) > tol {
+					t.Errorf("Dirichlet loser extreme symmetric mismatch. alphas: %v tol: %g got: %v", a, tol, v)
+				}
+			}
+		}
+	}
+	// Two components through the integration, against the closed form where
+	// it is accurate, and the normal approximation where it is not.
+	for _, a := range [][]float64{
+		{1e-3, 2e-3}, {1e-3, 1}, {0.01, 1}, {0.1, 0.3}, {1e-3, 1e7},
+		{1e4, 1e4 + 100}, {1e6, 1e6 + 2000}, {1e7, 1e7 + 300}, {1e7, 1e7 + 3000},
+	} {
+		want := 1 - mathext.RegIncBeta(a[0], a[1], 0.5)
+		wantTol := 1e-12
+		if a[0] > maxRegIncBetaAlpha {
+			want = dirichletWinnerNormalApprox(a, 1e-12, false)[0]
+			wantTol = 1 / (100 * a[0])
+		}
+		for _, tol := range []float64{1e-8, 1e-11} {
+			w, wok := dirichletWinnerQuad(a, tol, false)
+			l, lok := dirichletWinnerQuad(a, tol, true)
+			if !wok || !lok || w.capped || l.capped {
+				t.Errorf("Dirichlet extreme two-component not converged. alphas: %v tol: %g", a, tol)
+			}
+			if math.Abs(w.result[0]-want) > 4*tol+wantTol || math.Abs(l.result[1]-want) > 4*tol+wantTol {
+				t.Errorf("Dirichlet extreme two-component mismatch. alphas: %v tol: %g winner: %v loser: %v want: %v", a, tol, w.result[0], l.result[1], want)
+			}
+		}
+		if a[0] > maxRegIncBetaAlpha {
+			if p := DirichletWinner(a, 1e-11); math.Abs(p[0]-want) > 1e-10+wantTol {
+				t.Errorf("Dirichlet extreme two-component closed form mismatch. alphas: %v got: %v want: %v", a, p[0], want)
+			}
+		}
+	}
+	// A negligible tiny alpha doesn't disturb the large ones.
+	a := []float64{1e-3, 1e6, 1e6 + 2000}
+	pair := DirichletWinner(a[1:], 1e-11)
+	r := DirichletWinner(a, 1e-11)
+	if r[0] > 1e-11 || math.Abs(r[1]-pair[0]) > 1e-10 || math.Abs(r[2]-pair[1]) > 1e-10 {
+		t.Errorf("Dirichlet extreme mixed mismatch. got: %v want: %v", r, pair)
+	}
+	// The normal approximation agrees with the integration around the switch.
+	a = []float64{1e8, 1e8 + 1e4, 1e8 + 5e3, 1e8 - 2e4}
+	q, _ := dirichletWinnerQuad(a, 1e-11, false)
+	for i, v := range dirichletWinnerNormalApprox(a, 1e-11, false) {
+		if math.Abs(v-q.result[i]) > 1e-9 {
+			t.Errorf("Dirichlet normal approx mismatch. alphas: %v got: %v want: %v", a, v, q.result[i])
+		}
+	}
+	// Random alphas spanning 1e-3 to 1e7.
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a := make([]float64, rnd.Intn(8)+3)
+		for j := range a {
+			a[j] = math.Pow(10, rnd.Float64()*10-3)
+		}
+		for _, tol := range []float64{1e-8, 1e-11} {
+			r := DirichletWinnerDetailed(a, tol)
+			if !r.Converged {
+				t.Errorf("Dirichlet extreme not converged. alphas: %v tol: %g", a, tol)
+			}
+			l := DirichletLoser(a, tol)
+			if math.Abs(floats.Sum(r.Probs)-1) > float64(2*len(a))*tol || math.Abs(floats.Sum(l)-1) > float64(2*len(a))*tol {
+				t.Errorf("Dirichlet extreme large sum error. alphas: %v tol: %g winner: %v loser: %v", a, tol, r.Probs, l)
+			}
+		}
+	}
+}
+
+func TestLogGammaIncReg(t *testing.T) {
+	// For a = 1, P(1, x) = 1-e^-x and Q(1, x) = e^-x, including where they
+	// or x underflow. For a = 2, Q(2, x) = (1+x)*e^-x.
+	for _, u := range []float64{-800, -700, -100, -1, 0, 1, 3, 6, 7, 10} {
+		x := math.Exp(u)
+		lp := math.Log(-math.Expm1(-x))
+		if x < 1e-300 {
+			lp = u
+		}
+		if got := logGammaIncReg(1, u); math.Abs(got-lp) > 1e-13*math.Abs(lp) {
+			t.Errorf("logGammaIncReg mismatch. u: %v got: %v want: %v", u, got, lp)
+		}
+		if got := logGammaIncRegComp(1, u); math.Abs(got+x) > 1e-13*math.Max(x, 1e-3) {
+			t.Errorf("logGammaIncRegComp mismatch. u: %v got: %v want: %v", u, got, -x)
+		}
+		if got, want := logGammaIncRegComp(2, u), math.Log1p(x)-x; math.Abs(got-want) > 1e-13*math.Max(x, 1e-3) {
+			t.Errorf("logGammaIncRegComp mismatch. a: 2 u: %v got: %v want: %v", u, got, want)
+		}
+	}
+	// Where neither underflows, they agree with the Gamma cdf and survival function.
+	for _, a := range []float64{1e-3, 0.5, 10, 1e4} {
+		for _, x := range []float64{1e-3, 0.5, 1, 5, 20, 1e4} {
+			if p := mathext.GammaIncReg(a, x); p > 1e-300 && math.Abs(logGammaIncReg(a, math.Log(x))-math.Log(p)) > 1e-12 {
+				t.Errorf("logGammaIncReg mismatch. a: %v x: %v got: %v want: %v", a, x, logGammaIncReg(a, math.Log(x)), math.Log(p))
+			}
+			if q := mathext.GammaIncRegComp(a, x); q > 1e-300 && math.Abs(logGammaIncRegComp(a, math.Log(x))-math.Log(q)) > 1e-12 {
+				t.Errorf("logGammaIncRegComp mismatch. a: %v x: %v got: %v want: %v", a, x, logGammaIncRegComp(a, math.Log(x)), math.Log(q))
+			}
+		}
+	}
+}
+
+func TestDirichletWinnerWorkspace(t *testing.T) {
+	var w DirichletWinnerWorkspace
+	r := rand.New(rand.NewSource(1))
+	for _, parallel := range []int{0, 1, 2, 3, 8} {
+		w.Parallel = parallel
+		for _, n := range []int{1, 2, 3, 10, 5, 20} {
+			a := make([]float64, n)
+			for j := range a {
+				a[j] = 1 + r.ExpFloat64()*10
+			}
+			for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+				want := DirichletWinner(a, tol)
+				got := w.DirichletWinner(nil, a, tol)
+				for i := range want {
+					// Up to 1<<minDepth parts match the serial integration,
+					// beyond that they only match within tolerance.
+					diff := 1e-14
+					if parallel > 1<<minDepth {
+						diff = float64(2*n) * tol
+					}
+					if (parallel < 2 && got[i] != want[i]) || math.Abs(got[i]-want[i]) > diff {
+						t.Errorf("Dirichlet workspace mismatch: parallel: %v alphas: %v tol: %g got: %v want: %v", parallel, a, tol, got[i], want[i])
+					}
+				}
+			}
+		}
+	}
+
+	w.Parallel = 0
+	a := []float64{5.5, 10.5, 15.5}
+	dst := make([]float64, len(a))
+	w.DirichletWinner(dst, a, 1e-8)
+	allocs := testing.AllocsPerRun(10, func() {
+		w.DirichletWinner(dst, a, 1e-8)
+	})
+	if allocs != 0 {
+		t.Errorf("Dirichlet workspace allocated %v times, want 0", allocs)
+	}
+}
+
 func BenchmarkDirichletWinner(b *testing.B) {
 	a := []float64{5.5, 10.5, 15.5}
 	for i := 0; i < b.N; i++ {
@@ -110,3 +528,96 @@ func BenchmarkDirichletWinnerHighTol(b *testing.B) {
 		DirichletWinner(a, 1e-3)
 	}
 }
+
+func TestDirichletLoser(t *testing.T) {
+	for _, a := range [][]float64{
+		{1},
+		{2, 3},
+		{5.5, 10.5, 15.5},
+		{1, 2, 3, 4, 5, 6},
+		{50.5, 100.5, 150.5},
+	} {
+		n := len(a)
+		r := DirichletRank(a, 1e-10)
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			l := DirichletLoser(a, tol)
+			var sum float64
+			for i, v := range l {
+				sum += v
+				if math.Abs(v-r[i][n-1]) > 2*tol+1e-10 {
+					t.Errorf("Dirichlet loser/rank mismatch. alphas: %v tol: %g loser: %v rank: %v", a, tol, v, r[i][n-1])
+				}
+			}
+			if math.Abs(sum-1) > float64(2*n)*tol {
+				t.Errorf("Dirichlet loser large sum error: %g tol: %g", 1-sum, tol)
+			}
+		}
+	}
+	// With two components, the loser is the winner reversed. Check this
+	// through the integration rather than the closed form.
+	for _, a := range [][]float64{{2, 3}, {1.5, 20}, {40, 41}} {
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			w, _ := dirichletWinnerQuad(a, tol, false)
+			l, _ := dirichletWinnerQuad(a, tol, true)
+			b := mathext.RegIncBeta(a[0], a[1], 0.5)
+			for i, want := range []float64{b, 1 - b} {
+				if math.Abs(l.result[i]-want) > 4*tol || math.Abs(l.result[i]-w.result[1-i]) > 4*tol {
+					t.Errorf("Dirichlet two-component loser mismatch. alphas: %v tol: %g loser: %v winner: %v want: %v", a, tol, l.result[i], w.result[1-i], want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkDirichletWinnerMethod compares the integration methods, reporting
+// the number of integrand evaluations.
+func BenchmarkDirichletWinnerMethod(b *testing.B) {
+	a := []float64{5.5, 10.5, 15.5}
+	for _, method := range []struct {
+		name   string
+		method QuadMethod
+	}{
+		{"Simpson", AdaptiveSimpson},
+		{"GaussKronrod", GaussKronrod},
+		{"TanhSinh", TanhSinh},
+	} {
+		for _, tol := range []float64{1e-3, 1e-8, 1e-13} {
+			b.Run(fmt.Sprintf("%s/tol=%g", method.name, tol), func(b *testing.B) {
+				var evals int
+				for i := 0; i < b.N; i++ {
+					evals = DirichletWinnerMethod(a, tol, method.method).Evaluations
+				}
+				b.ReportMetric(float64(evals), "evals/op")
+			})
+		}
+	}
+}
+
+func BenchmarkDirichletWinnerWorkspace(b *testing.B) {
+	a := []float64{5.5, 10.5, 15.5}
+	dst := make([]float64, len(a))
+	var w DirichletWinnerWorkspace
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.DirichletWinner(dst, a, 1e-8)
+	}
+}
+
+func BenchmarkDirichletWinnerLarge(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{1000, 4000} {
+		a := make([]float64, n)
+		for j := range a {
+			a[j] = 100 + r.ExpFloat64()*10
+		}
+		dst := make([]float64, n)
+		for _, parallel := range []int{1, 2, 4, 8} {
+			w := DirichletWinnerWorkspace{Parallel: parallel}
+			b.Run(fmt.Sprintf("n=%d/parallel=%d", n, parallel), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					w.DirichletWinner(dst, a, 1e-8)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package statext
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// dirichletWinnerGradAdaptiveQuadFunc is the function to integrate for the
+// dirichlet winner probs and their derivatives. The first n entries of result
+// are the integrands of the winner probs, as dirichletWinnerAdaptiveQuadFunc,
+// and entry n+i*n+j is the integrand of d(probs[i])/d(alphas[j]).
+// digammas holds the digamma function of each alpha, and dlogCDFs is scratch
+// space of length n.
+// Works in-place on result array.
+func dirichletWinnerGradAdaptiveQuadFunc(mu, sigma, y float64, alphas, logAlphas, norms, digammas, dlogCDFs, result []float64) {
+	n := len(alphas)
+	probs := result[:n]
+	dirichletWinnerAdaptiveQuadFunc(mu, sigma, y, alphas, logAlphas, norms, probs, logGammaIncReg)
+	if y == 0.0 || y == 1.0 {
+		for k := n; k < len(result); k++ {
+			result[k] = 0.0
+		}
+		return
+	}
+	u := mu + sigma*(math.Log(y)-math.Log1p(-y))
+	// Differentiating gamma(alphas[i]).pdf(x)*product(gamma(alphas[k]).cdf(x), k!=i)
+	// with respect to alphas[j] multiplies it by d(log(gamma(alphas[j]).cdf(x)))/d(alphas[j])
+	// for j != i, and by d(log(gamma(alphas[i]).pdf(x)))/d(alphas[i]) = log(x) - digamma(alphas[i])
+	// for j == i.
+	for j, alpha := range alphas {
+		dlogCDFs[j] = logGammaIncRegDa(alpha, u)
+	}
+	for i, p := range probs {
+		row := result[n+i*n : n+(i+1)*n]
+		if p == 0.0 {
+			for j := range row {
+				row[j] = 0.0
+			}
+			continue
+		}
+		for j := range row {
+			row[j] = p * dlogCDFs[j]
+		}
+		row[i] = p * (u - digammas[i])
+	}
+}
+
+// logGammaIncRegDa returns the derivative with respect to a of the log of the
+// regularized lower incomplete Gamma function P(a, x) at x = exp(u),
+// remaining accurate where P or x underflow.
+func logGammaIncRegDa(a, u float64) float64 {
+	x := math.Exp(u)
+	if x <= a+1 {
+		// P(a, x) = x^a*e^-x * Σ x^k/Γ(a+k+1), so differentiating each term,
+		// d(log(P))/da = log(x) - Σ t_k*digamma(a+k+1) / Σ t_k
+		// with t_k = x^k/((a+1)...(a+k)).
+		psi := mathext.Digamma(a + 1)
+		sum, wsum, term := 1.0, psi, 1.0
+		for k := 1.0; k < 10000 && term > sum*1e-17; k++ {
+			psi += 1 / (a + k)
+			term *= x / (a + k)
+			sum += term
+			wsum += term * psi
+		}
+		return u - wsum/sum
+	}
+	if math.IsInf(x, 1) {
+		return 0.0
+	}
+	// Q(a, x) = x^a*e^-x/Γ(a) / f, with f the continued fraction
+	//  x+1-a- 1(1-a)/(x+3-a- 2(2-a)/(x+5-a- ...)),
+	// so d(log(Q))/da = log(x) - digamma(a) - f'/f, and
+	// d(log(P))/da = -Q/P * d(log(Q))/da.
+	// f and f' are evaluated by the forward recurrence of the convergents,
+	// differentiated with respect to a, and rescaled each step.
+	aPrev, bPrev, daPrev, dbPrev := 1.0, 0.0, 0.0, 0.0
+	aCur, bCur, daCur, dbCur := x+1-a, 1.0, -1.0, 0.0
+	f, df := aCur, daCur
+	for i := 1.0; i < 10000; i++ {
+		an, b := -i*(i-a), x+2*i+1-a
+		aNext := b*aCur + an*aPrev
+		bNext := b*bCur + an*bPrev
+		daNext := b*daCur - aCur + an*daPrev + i*aPrev
+		dbNext := b*dbCur - bCur + an*dbPrev + i*bPrev
+		aPrev, bPrev, daPrev, dbPrev = aCur, bCur, daCur, dbCur
+		aCur, bCur, daCur, dbCur = aNext, bNext, daNext, dbNext
+		if bCur == 0 {
+			continue
+		}
+		scale := 1 / bCur
+		aPrev, bPrev, daPrev, dbPrev = aPrev*scale, bPrev*scale, daPrev*scale, dbPrev*scale
+		aCur, bCur, daCur, dbCur = aCur*scale, 1.0, daCur*scale, dbCur*scale
+		fNext, dfNext := aCur, daCur-aCur*dbCur
+		done := math.Abs(fNext-f) <= 1e-16*math.Abs(fNext) && math.Abs(dfNext-df) <= 1e-15*math.Abs(dfNext)
+		f, df = fNext, dfNext
+		if done {
+			break
+		}
+	}
+	lg, _ := math.Lgamma(a)
+	logQ := a*u - x - lg - math.Log(f)
+	q := math.Exp(logQ)
+	if q == 0.0 {
+		return 0.0
+	}
+	p := -math.Expm1(logQ)
+	return -q / p * (u - mathext.Digamma(a) - df/f)
+}
+
+// DirichletWinnerJacobian computes the probabilities that each output value
+// of the Dirichlet distribution will be the largest, as DirichletWinner, and
+// their derivatives with respect to the alphas. Entry [i][j] of jac is
+// d(probs[i])/d(alphas[j]). Each column of jac sums to 0, as the probs sum
+// to 1.
+// Uses the same adaptive quadrature integration technique as DirichletWinner,
+// differentiating under the integral: the derivative of the Gamma pdf with
+// respect to its shape alpha is the pdf times log(x) - digamma(alpha), and
+// the derivative of the Gamma cdf is computed from the series or continued
+// fraction of the regularized incomplete Gamma function, differentiated
+// term by term.
+// DirichletWinnerJacobian returns nil slices if tol is NaN or less than
+// MinDirichletWinnerTol.
+func DirichletWinnerJacobian(alphas []float64, tol float64) (probs []float64, jac [][]float64) {
+	if !(tol >= MinDirichletWinnerTol) {
+		return nil, nil
+	}
+	n := len(alphas)
+	probs = make([]float64, n)
+	jac = make([][]float64, n)
+	flat := make([]float64, n*n)
+	for i := range jac {
+		jac[i] = flat[i*n : (i+1)*n]
+	}
+	switch n {
+	case 0:
+		return probs, jac
+	case 1:
+		probs[0] = 1.0
+		return probs, jac
+	}
+	logAlphas := make([]float64, n)
+	norms := make([]float64, n)
+	digammas := make([]float64, n)
+	dlogCDFs := make([]float64, n)
+	mu, sigma := dirichletWinnerPrepare(alphas, logAlphas, norms, false)
+	for j, alpha := range alphas {
+		digammas[j] = mathext.Digamma(alpha)
+	}
+	q := newAdaptiveQuad(func(y float64, dst []float64) {
+		dirichletWinnerGradAdaptiveQuadFunc(mu, sigma, y, alphas, logAlphas, norms, digammas, dlogCDFs, dst)
+	}, n+n*n, tol)
+	q.integrate(n, 1, float64(2*n)*tol)
+	copy(probs, q.result[:n])
+	copy(flat, q.result[n:])
+	return probs, jac
+}
@@ -0,0 +1,75 @@
+package statext
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+func TestLogGammaIncRegDa(t *testing.T) {
+	// Check against central differences of log(GammaIncReg), on both sides
+	// of the switch from the series to the continued fraction at x = a+1.
+	for _, a := range []float64{0.3, 1, 2.5, 10, 75, 400} {
+		for _, r := range []float64{0.05, 0.5, 0.9, 1.1, 1.5, 3} {
+			x := a*r + 0.1
+			const h = 1e-5
+			want := (math.Log(mathext.GammaIncReg(a+h, x)) - math.Log(mathext.GammaIncReg(a-h, x))) / (2 * h)
+			got := logGammaIncRegDa(a, math.Log(x))
+			if math.Abs(got-want) > 1e-6*math.Max(1, math.Abs(want)) {
+				t.Errorf("logGammaIncRegDa mismatch at a=%v, x=%v: got %v want %v", a, x, got, want)
+			}
+		}
+	}
+	// Where P underflows, d(log(P))/da tends to log(x) - digamma(a+1).
+	if got, want := logGammaIncRegDa(2, -800), -800-mathext.Digamma(3); math.Abs(got-want) > 1e-12*math.Abs(want) {
+		t.Errorf("logGammaIncRegDa mismatch at underflow: got %v want %v", got, want)
+	}
+}
+
+func TestDirichletWinnerJacobian(t *testing.T) {
+	for _, a := range [][]float64{
+		{1},
+		{2, 3},
+		{0.5, 0.8, 1.2},
+		{5.5, 10.5, 15.5},
+		{1, 2, 3, 4, 5, 6},
+		{50.5, 100.5, 150.5},
+		{400, 410, 420, 430},
+	} {
+		n := len(a)
+		const tol = 1e-11
+		probs, jac := DirichletWinnerJacobian(a, tol)
+		w := DirichletWinner(a, tol)
+		for i := range a {
+			if math.Abs(probs[i]-w[i]) > 2*tol {
+				t.Errorf("Jacobian/winner mismatch. alphas: %v probs: %v winner: %v", a, probs, w)
+			}
+		}
+		for j := range a {
+			// Compare with central differences of DirichletWinner.
+			h := 1e-4 * a[j]
+			ap := append([]float64(nil), a...)
+			am := append([]float64(nil), a...)
+			ap[j] += h
+			am[j] -= h
+			wp, wm := DirichletWinner(ap, tol), DirichletWinner(am, tol)
+			var colSum float64
+			for i := range a {
+				want := (wp[i] - wm[i]) / (2 * h)
+				if math.Abs(jac[i][j]-want) > 1e-5*math.Max(1, math.Abs(want)) {
+					t.Errorf("Jacobian mismatch. alphas: %v entry [%v][%v]: got %v want %v", a, i, j, jac[i][j], want)
+				}
+				colSum += jac[i][j]
+			}
+			if math.Abs(colSum) > float64(2*n)*1e-8 {
+				t.Errorf("Jacobian column sum error. alphas: %v column %v sum: %v", a, j, colSum)
+			}
+		}
+	}
+	for _, tol := range []float64{0, -1, math.NaN(), 1e-15} {
+		if probs, jac := DirichletWinnerJacobian([]float64{1, 2}, tol); probs != nil || jac != nil {
+			t.Errorf("DirichletWinnerJacobian accepted tol %v: got %v, %v", tol, probs, jac)
+		}
+	}
+}
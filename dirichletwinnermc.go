@@ -0,0 +1,114 @@
+package statext
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// DirichletWinnerMCResult holds the result of DirichletWinnerMC.
+type DirichletWinnerMCResult struct {
+	// Probs holds the estimated probabilities that each output value of the
+	// Dirichlet distribution will be the largest.
+	Probs []float64
+	// Lower and Upper hold the bounds of the Wilson score interval of each
+	// entry in Probs, at the requested confidence level.
+	Lower, Upper []float64
+}
+
+// DirichletWinnerMC estimates the probabilities that each output value of
+// the Dirichlet distribution will be the largest by Monte Carlo, drawing
+// samples from distmv.Dirichlet with the random source src. It is useful as
+// a cross-check of DirichletWinner, and for very many alphas, as each sample
+// costs O(len(alphas)).
+//
+// level is the confidence level of the returned intervals, such as 0.95.
+// If conditional is set, each sample x of the Dirichlet distribution is
+// scaled by an independent Gamma(sum(alphas)) sample s into independent
+// Gamma samples s*x, and the estimate for output j is instead the average of
+//  product(gamma(alphas[i]).cdf(s*x[j]), i != j)
+// the probability that output j is the largest given its value. This
+// conditional Monte Carlo estimate has lower variance, particularly for the
+// small probabilities, but costs O(len(alphas)^2) per sample. Its intervals
+// are Wilson score intervals with the effective sample size
+// p*(1-p)/variance of the estimate.
+//
+// Alphas much smaller than 1 can underflow the samples of distmv.Dirichlet,
+// biasing the estimates towards the first outputs. DirichletWinnerMC panics
+// if samples is less than 1 or level is not in (0, 1).
+func DirichletWinnerMC(alphas []float64, samples int, level float64, conditional bool, src rand.Source) DirichletWinnerMCResult {
+	if samples < 1 {
+		panic("dirichlet winner: no samples")
+	}
+	if !(level > 0 && level < 1) {
+		panic("dirichlet winner: bad confidence level")
+	}
+	n := len(alphas)
+	res := DirichletWinnerMCResult{
+		Probs: make([]float64, n),
+		Lower: make([]float64, n),
+		Upper: make([]float64, n),
+	}
+	if n == 0 {
+		return res
+	}
+	z := distuv.UnitNormal.Quantile(1 - (1-level)/2)
+	dir := distmv.NewDirichlet(alphas, src)
+	x := make([]float64, n)
+	if !conditional {
+		for k := 0; k < samples; k++ {
+			dir.Rand(x)
+			res.Probs[floats.MaxIdx(x)]++
+		}
+		floats.Scale(1/float64(samples), res.Probs)
+		for j, p := range res.Probs {
+			res.Lower[j], res.Upper[j] = wilsonInterval(p, float64(samples), z)
+		}
+		return res
+	}
+	scale := distuv.Gamma{Alpha: floats.Sum(alphas), Beta: 1, Src: src}
+	// Welford's running mean and sum of squared deviations of each estimate.
+	m2 := make([]float64, n)
+	for k := 0; k < samples; k++ {
+		dir.Rand(x)
+		logS := math.Log(scale.Rand())
+		for j := range x {
+			u := logS + math.Log(x[j])
+			var logCDFs float64
+			for i, alpha := range alphas {
+				if i != j {
+					logCDFs += logGammaIncReg(alpha, u)
+				}
+			}
+			v := math.Exp(logCDFs)
+			d := v - res.Probs[j]
+			res.Probs[j] += d / float64(k+1)
+			m2[j] += d * (v - res.Probs[j])
+		}
+	}
+	for j, p := range res.Probs {
+		// The variance of the estimate, m2/(samples*(samples-1)), is that of
+		// a proportion from p*(1-p)/variance trials.
+		nEff := math.Inf(1)
+		if samples > 1 && m2[j] > 0 {
+			nEff = p * (1 - p) * float64(samples) * float64(samples-1) / m2[j]
+		}
+		res.Lower[j], res.Upper[j] = wilsonInterval(p, nEff, z)
+	}
+	return res
+}
+
+// wilsonInterval returns the Wilson score interval of a proportion p
+// observed in n trials, for the standard normal quantile z.
+func wilsonInterval(p, n, z float64) (lower, upper float64) {
+	if math.IsInf(n, 1) {
+		return p, p
+	}
+	z2n := z * z / n
+	centre := (p + z2n/2) / (1 + z2n)
+	half := z / (1 + z2n) * math.Sqrt(p*(1-p)/n+z2n/(4*n))
+	return math.Max(centre-half, 0), math.Min(centre+half, 1)
+}
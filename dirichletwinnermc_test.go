@@ -0,0 +1,87 @@
+package statext
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestDirichletWinnerMC(t *testing.T) {
+	for _, a := range [][]float64{
+		{1},
+		{2, 3},
+		{5.5, 10.5, 15.5},
+		{1, 2, 3, 4, 5, 6},
+		{0.5, 0.7, 1.5, 2},
+	} {
+		want := DirichletWinner(a, 1e-10)
+		plain := DirichletWinnerMC(a, 20000, 0.999, false, rand.NewSource(1))
+		cond := DirichletWinnerMC(a, 20000, 0.999, true, rand.NewSource(1))
+		var sum float64
+		for i, w := range want {
+			sum += plain.Probs[i]
+			for _, r := range []DirichletWinnerMCResult{plain, cond} {
+				if !(r.Lower[i] <= r.Probs[i] && r.Probs[i] <= r.Upper[i]) {
+					t.Errorf("Dirichlet MC estimate outside interval. alphas: %v estimate: %v interval: [%v, %v]", a, r.Probs[i], r.Lower[i], r.Upper[i])
+				}
+				if w < r.Lower[i]-1e-12 || w > r.Upper[i]+1e-12 {
+					t.Errorf("Dirichlet MC interval mismatch. alphas: %v want: %v interval: [%v, %v]", a, w, r.Lower[i], r.Upper[i])
+				}
+			}
+			// The conditional estimate is more precise.
+			if len(a) > 1 && cond.Upper[i]-cond.Lower[i] >= plain.Upper[i]-plain.Lower[i] {
+				t.Errorf("Dirichlet MC conditional interval not narrower. alphas: %v conditional: [%v, %v] plain: [%v, %v]", a, cond.Lower[i], cond.Upper[i], plain.Lower[i], plain.Upper[i])
+			}
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("Dirichlet MC sum mismatch. alphas: %v sum: %v", a, sum)
+		}
+	}
+
+	// The estimates are reproducible from the source.
+	a := []float64{1, 2, 3}
+	for _, conditional := range []bool{false, true} {
+		r1 := DirichletWinnerMC(a, 1000, 0.95, conditional, rand.NewSource(2))
+		r2 := DirichletWinnerMC(a, 1000, 0.95, conditional, rand.NewSource(2))
+		for i := range a {
+			if r1.Probs[i] != r2.Probs[i] || r1.Lower[i] != r2.Lower[i] || r1.Upper[i] != r2.Upper[i] {
+				t.Errorf("Dirichlet MC not reproducible. conditional: %v got: %+v want: %+v", conditional, r2, r1)
+			}
+		}
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	for _, test := range []struct {
+		p, n, z      float64
+		lower, upper float64
+	}{
+		{0.5, 100, 1.96, 0.4038, 0.5962},
+		{0.1, 10, 1.96, 0.0179, 0.4042},
+		{0, 10, 1.96, 0, 0.2775},
+		{1, 10, 1.96, 0.7225, 1},
+		{0.3, math.Inf(1), 1.96, 0.3, 0.3},
+	} {
+		lower, upper := wilsonInterval(test.p, test.n, test.z)
+		if math.Abs(lower-test.lower) > 1e-4 || math.Abs(upper-test.upper) > 1e-4 {
+			t.Errorf("Wilson interval mismatch. p: %v n: %v got: [%v, %v] want: [%v, %v]", test.p, test.n, lower, upper, test.lower, test.upper)
+		}
+	}
+}
+
+func BenchmarkDirichletWinnerMC(b *testing.B) {
+	a := []float64{5.5, 10.5, 15.5}
+	src := rand.NewSource(1)
+	for i := 0; i < b.N; i++ {
+		DirichletWinnerMC(a, 1000, 0.95, false, src)
+	}
+}
+
+func BenchmarkDirichletWinnerMCConditional(b *testing.B) {
+	a := []float64{5.5, 10.5, 15.5}
+	src := rand.NewSource(1)
+	for i := 0; i < b.N; i++ {
+		DirichletWinnerMC(a, 1000, 0.95, true, src)
+	}
+}
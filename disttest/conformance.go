@@ -0,0 +1,148 @@
+package disttest
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Options configures Conformance. The zero value runs the checks for a
+// continuous distribution with the default settings.
+type Options struct {
+	// Case identifies the distribution in failure messages.
+	Case int
+	// Samples is the number of random samples drawn. If zero, 1e6 samples
+	// are drawn.
+	Samples int
+	// Tol is the tolerance of the checks of the density, mean, variance,
+	// median, entropy and CDF against the samples. If zero, 1e-2 is used.
+	Tol float64
+	// MomentTol is the tolerance of the skewness and excess kurtosis, whose
+	// sample estimates are noisier. If zero, 1e-1 is used.
+	MomentTol float64
+	// Discrete is whether the distribution is integer-valued, in which case
+	// its Prob is a mass function rather than a density.
+	Discrete bool
+	// Min is the lower bound of the support of a continuous distribution,
+	// from which the density is integrated. If both Min and Max are zero,
+	// the support is taken as the whole real line.
+	Min, Max float64
+	// Bins is the number of empirical quantiles at which the integral of the
+	// density is checked. If zero, 50 are used.
+	Bins int
+	// FalseFailureRate is the significance level of the G-test of the
+	// samples of a discrete distribution, the probability with which a
+	// correct distribution fails it. If zero, 1e-3 is used.
+	FalseFailureRate float64
+}
+
+// Conformance draws random samples from dist and runs every applicable check
+// of this package against them, detecting which methods dist implements.
+// Properties that dist reports as NaN, such as the moments of heavy-tailed
+// distributions, are taken to be undefined and not checked. Neither are the
+// mean where the variance is undefined, nor the variance where the excess
+// kurtosis is undefined, as their sample estimates don't settle.
+//
+// For continuous distributions, the checks are CheckMean, CheckVarAndStd,
+// CheckSkewness, CheckExKurtosis, CheckMedian, CheckEntropy,
+// CheckRandLogProbContinuous, CheckProbContinuous, CheckQuantileCDFSurvival
+// and CheckProbQuantContinuous. For discrete distributions,
+// CheckRandLogProbDiscrete, CheckProbDiscrete and CheckCDFSurvival replace
+// the density and CDF checks.
+func Conformance(t testing.TB, dist distuv.Rander, opts Options) {
+	t.Helper()
+	if opts.Samples == 0 {
+		opts.Samples = 1e6
+	}
+	if opts.Tol == 0 {
+		opts.Tol = 1e-2
+	}
+	if opts.MomentTol == 0 {
+		opts.MomentTol = 1e-1
+	}
+	if opts.Bins == 0 {
+		opts.Bins = 50
+	}
+	if opts.FalseFailureRate == 0 {
+		opts.FalseFailureRate = 1e-3
+	}
+	if opts.Min == 0 && opts.Max == 0 {
+		opts.Min, opts.Max = math.Inf(-1), math.Inf(1)
+	}
+	i, tol := opts.Case, opts.Tol
+	x := make([]float64, opts.Samples)
+	GenerateSamples(x, dist)
+	sort.Float64s(x)
+	if opts.Discrete {
+		for _, v := range x {
+			if v != math.Floor(v) {
+				t.Errorf("Non-integer sample of discrete distribution case %v: %v", i, v)
+				break
+			}
+		}
+	} else if x[0] < opts.Min || x[len(x)-1] > opts.Max {
+		t.Errorf("Sample outside support case %v: [%v, %v] not within [%v, %v]", i, x[0], x[len(x)-1], opts.Min, opts.Max)
+	}
+
+	// The sample mean and variance only settle if the next moments are
+	// finite, so they're skipped where those are known to be undefined.
+	varDefined, kurtDefined := true, true
+	if v, ok := dist.(VarStder); ok {
+		varDefined = defined(v.Variance())
+	}
+	if e, ok := dist.(ExKurtosiser); ok {
+		kurtDefined = defined(e.ExKurtosis())
+	}
+	if m, ok := dist.(Meaner); ok && defined(m.Mean()) && varDefined {
+		CheckMean(t, i, x, m, tol)
+	}
+	if v, ok := dist.(VarStder); ok && varDefined && kurtDefined {
+		CheckVarAndStd(t, i, x, v, tol)
+	}
+	if s, ok := dist.(Skewnesser); ok && defined(s.Skewness()) {
+		CheckSkewness(t, i, x, s, opts.MomentTol)
+	}
+	if e, ok := dist.(ExKurtosiser); ok && defined(e.ExKurtosis()) {
+		CheckExKurtosis(t, i, x, e, opts.MomentTol)
+	}
+	if m, ok := dist.(Medianer); ok && !opts.Discrete {
+		// The sample median of a discrete distribution needn't match.
+		CheckMedian(t, i, x, m, tol)
+	}
+	if e, ok := dist.(Entropyer); ok && defined(e.Entropy()) {
+		CheckEntropy(t, i, x, e, tol)
+	}
+
+	if opts.Discrete {
+		if l, ok := dist.(distuv.LogProber); ok {
+			CheckRandLogProbDiscrete(t, i, x, l, opts.FalseFailureRate)
+		}
+		if p, ok := dist.(ProbLogProber); ok {
+			CheckProbDiscrete(t, i, x, p, tol)
+		}
+		if c, ok := dist.(CDFer); ok {
+			CheckCDFSurvival(t, i, x, c, tol)
+		}
+		return
+	}
+	if l, ok := dist.(distuv.LogProber); ok {
+		CheckRandLogProbContinuous(t, i, opts.Min, x, l, tol, opts.Bins)
+	}
+	if p, ok := dist.(ProbLogProber); ok {
+		CheckProbContinuous(t, i, x, p, tol)
+	}
+	if c, ok := dist.(Cumulanter); ok {
+		CheckQuantileCDFSurvival(t, i, x, c, tol)
+	}
+	if c, ok := dist.(CumulantProber); ok {
+		CheckProbQuantContinuous(t, i, x, c, tol)
+	}
+}
+
+// defined reports whether a property of a distribution is defined, that is,
+// neither NaN nor infinite.
+func defined(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
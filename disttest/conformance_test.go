@@ -0,0 +1,93 @@
+package disttest_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/argusdusty/statext"
+	"github.com/argusdusty/statext/disttest"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// recorder records the failures of the checks, rather than failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestConformance(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	disttest.Conformance(t, distuv.Normal{Mu: 1, Sigma: 2, Src: src}, disttest.Options{Samples: 1e5})
+	disttest.Conformance(t, statext.BetaPrime{Alpha: 12, Beta: 16, Src: src}, disttest.Options{Case: 1, Samples: 1e5, Min: 0, Max: math.Inf(1)})
+	disttest.Conformance(t, statext.BetaBinomial{Alpha: 12, Beta: 16, N: 20, Src: src}, disttest.Options{Case: 2, Samples: 1e5, Discrete: true})
+	// The moments of a BetaPrime with small Beta are undefined, so they
+	// aren't checked.
+	disttest.Conformance(t, statext.BetaPrime{Alpha: 3, Beta: 1.5, Src: src}, disttest.Options{Case: 3, Samples: 1e5, Min: 0, Max: math.Inf(1)})
+}
+
+// misscaled reports the standard deviation of a different Normal.
+type misscaled struct {
+	distuv.Normal
+}
+
+func (m misscaled) StdDev() float64   { return 2 * m.Normal.StdDev() }
+func (m misscaled) Variance() float64 { return 4 * m.Normal.Variance() }
+
+func TestConformanceFailure(t *testing.T) {
+	r := &recorder{TB: t}
+	d := misscaled{distuv.Normal{Mu: 0, Sigma: 1, Src: rand.NewSource(1)}}
+	disttest.Conformance(r, d, disttest.Options{Samples: 1e5})
+	if len(r.errors) == 0 {
+		t.Errorf("Conformance didn't detect a wrong variance")
+	}
+}
+
+// shifted reports the masses of a Poisson distribution with a different rate.
+type shifted struct {
+	distuv.Poisson
+}
+
+func (s shifted) LogProb(x float64) float64 {
+	return distuv.Poisson{Lambda: s.Lambda * 1.02}.LogProb(x)
+}
+
+func TestCheckRandLogProbDiscrete(t *testing.T) {
+	// Correct distributions fail at about the false failure rate.
+	const (
+		trials = 400
+		alpha  = 0.05
+	)
+	src := rand.New(rand.NewSource(1))
+	x := make([]float64, 2000)
+	failures := 0
+	for k := 0; k < trials; k++ {
+		d := distuv.Binomial{N: 30, P: 0.3, Src: src}
+		disttest.GenerateSamples(x, d)
+		r := &recorder{TB: t}
+		disttest.CheckRandLogProbDiscrete(r, k, x, d, alpha)
+		if len(r.errors) > 0 {
+			failures++
+		}
+	}
+	// The failures are binomially distributed, with standard deviation
+	// about 4.4.
+	if failures < 5 || failures > 40 {
+		t.Errorf("CheckRandLogProbDiscrete false failures: got %v of %v want about %v", failures, trials, alpha*trials)
+	}
+
+	// A slightly wrong distribution fails with enough samples.
+	x = make([]float64, 1e5)
+	d := shifted{distuv.Poisson{Lambda: 10, Src: src}}
+	disttest.GenerateSamples(x, d)
+	r := &recorder{TB: t}
+	disttest.CheckRandLogProbDiscrete(r, 0, x, d, 1e-3)
+	if len(r.errors) == 0 {
+		t.Errorf("CheckRandLogProbDiscrete didn't detect a wrong LogProb")
+	}
+}
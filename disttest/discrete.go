@@ -0,0 +1,77 @@
+package disttest
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// minExpected is the least expected count of a bin of CheckRandLogProbDiscrete,
+// below which the chi-square approximation of the G statistic is poor.
+const minExpected = 5
+
+// CheckRandLogProbDiscrete checks that LogProb and Rand give consistent
+// results for a discrete distribution, with a G-test of the counts of the
+// samples x against the probability masses. Adjacent integers are pooled
+// into bins of expected count at least 5, and the mass outside the range of
+// the samples forms one more bin. The check fails if the p-value of the test
+// is below alpha, so a correct distribution fails with probability about
+// alpha; use a seeded source for reproducible results.
+func CheckRandLogProbDiscrete(t testing.TB, i int, x []float64, f distuv.LogProber, alpha float64) {
+	t.Helper()
+	n := float64(len(x))
+	counts := make(map[float64]float64)
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range x {
+		if v != math.Floor(v) {
+			t.Errorf("Non-integer sample case %v: %v", i, v)
+			return
+		}
+		counts[v]++
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	var obs, exp []float64
+	var o, e, total float64
+	for k := min; k <= max; k++ {
+		p := math.Exp(f.LogProb(k))
+		o += counts[k]
+		e += n * p
+		total += p
+		if e >= minExpected {
+			obs = append(obs, o)
+			exp = append(exp, e)
+			o, e = 0, 0
+		}
+	}
+	// The remainder of the range, and the mass outside it, which was never
+	// sampled.
+	e += n * math.Max(1-total, 0)
+	if e >= minExpected || len(obs) == 0 {
+		obs = append(obs, o)
+		exp = append(exp, e)
+	} else {
+		obs[len(obs)-1] += o
+		exp[len(exp)-1] += e
+	}
+	if len(obs) < 2 {
+		// A single bin is certain, so there's nothing to test.
+		return
+	}
+	// The (o - exp[b]) terms sum to 0 for a normalized LogProb, but keep the
+	// statistic sensitive to masses that don't sum to 1.
+	var g float64
+	for b, o := range obs {
+		g -= 2 * (o - exp[b])
+		if o > 0 {
+			g += 2 * o * math.Log(o/exp[b])
+		}
+	}
+	// The G statistic is approximately chi-square distributed with one
+	// degree of freedom fewer than the number of bins.
+	pValue := distuv.ChiSquared{K: float64(len(obs) - 1)}.Survival(g)
+	if pValue < alpha {
+		t.Errorf("Mismatch between samples and LogProb. Case %v. G-test statistic %v with %v bins, p-value %v", i, g, len(obs), pValue)
+	}
+}
@@ -2,7 +2,10 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package statext
+// Package disttest provides checks of the consistency of probability
+// distributions in gonum format, comparing their methods against each other
+// and against random samples.
+package disttest
 
 import (
 	"math"
@@ -14,81 +17,102 @@ import (
 	"gonum.org/v1/gonum/stat/distuv"
 )
 
-type meaner interface {
+// Meaner is a distribution with a mean.
+type Meaner interface {
 	Mean() float64
 }
 
-type quantiler interface {
+// Quantiler is a distribution with a quantile function.
+type Quantiler interface {
 	Quantile(float64) float64
 }
 
-type medianer interface {
-	quantiler
+// Medianer is a distribution with a median.
+type Medianer interface {
+	Quantiler
 	Median() float64
 }
 
-type varStder interface {
+// VarStder is a distribution with a variance and standard deviation.
+type VarStder interface {
 	StdDev() float64
 	Variance() float64
 }
 
-type entropyer interface {
+// Entropyer is a distribution with a log density and an entropy.
+type Entropyer interface {
 	distuv.LogProber
 	Entropy() float64
 }
 
-type exKurtosiser interface {
+// ExKurtosiser is a distribution with a mean and an excess kurtosis.
+type ExKurtosiser interface {
 	ExKurtosis() float64
 	Mean() float64
 }
 
-type skewnesser interface {
+// Skewnesser is a distribution with a mean, standard deviation and skewness.
+type Skewnesser interface {
 	StdDev() float64
 	Mean() float64
 	Skewness() float64
 }
 
-type cdfer interface {
+// CDFer is a distribution with a cumulative distribution function and a
+// survival function.
+type CDFer interface {
 	CDF(x float64) float64
 	Survival(x float64) float64
 }
 
-type cumulanter interface {
+// Cumulanter is a CDFer with a quantile function.
+type Cumulanter interface {
 	distuv.Quantiler
-	cdfer
+	CDFer
 }
 
-func generateSamples(x []float64, r distuv.Rander) {
+// GenerateSamples fills x with random samples drawn from r.
+func GenerateSamples(x []float64, r distuv.Rander) {
 	for i := range x {
 		x[i] = r.Rand()
 	}
 }
 
-type probLogprober interface {
+// ProbLogProber is a distribution with a density (or mass) function and its
+// logarithm.
+type ProbLogProber interface {
 	Prob(x float64) float64
 	LogProb(x float64) float64
 }
 
-type cumulantProber interface {
-	cumulanter
-	probLogprober
+// CumulantProber is a Cumulanter with a density function.
+type CumulantProber interface {
+	Cumulanter
+	ProbLogProber
 }
 
-func checkMean(t *testing.T, i int, x []float64, m meaner, tol float64) {
+// CheckMean checks the mean of m against that of the samples x.
+func CheckMean(t testing.TB, i int, x []float64, m Meaner, tol float64) {
+	t.Helper()
 	mean := stat.Mean(x, nil)
 	if !floats.EqualWithinAbsOrRel(mean, m.Mean(), tol, tol) {
 		t.Errorf("Mean mismatch case %v: want: %v, got: %v", i, mean, m.Mean())
 	}
 }
 
-func checkMedian(t *testing.T, i int, x []float64, m medianer, tol float64) {
+// CheckMedian checks the median of m against that of the sorted samples x.
+func CheckMedian(t testing.TB, i int, x []float64, m Medianer, tol float64) {
+	t.Helper()
 	median := stat.Quantile(0.5, stat.Empirical, x, nil)
 	if !floats.EqualWithinAbsOrRel(median, m.Median(), tol, tol) {
 		t.Errorf("Median mismatch case %v: want: %v, got: %v", i, median, m.Median())
 	}
 }
 
-func checkVarAndStd(t *testing.T, i int, x []float64, v varStder, tol float64) {
+// CheckVarAndStd checks the variance and standard deviation of v against
+// those of the samples x.
+func CheckVarAndStd(t testing.TB, i int, x []float64, v VarStder, tol float64) {
+	t.Helper()
 	variance := stat.Variance(x, nil)
 	if !floats.EqualWithinAbsOrRel(variance, v.Variance(), tol, tol) {
 		t.Errorf("Variance mismatch case %v: want: %v, got: %v", i, variance, v.Variance())
@@ -99,7 +123,10 @@ func checkVarAndStd(t *testing.T, i int, x []float64, v varStder, tol float64) {
 	}
 }
 
-func checkEntropy(t *testing.T, i int, x []float64, e entropyer, tol float64) {
+// CheckEntropy checks the entropy of e against the average of -e.LogProb
+// over the samples x.
+func CheckEntropy(t testing.TB, i int, x []float64, e Entropyer, tol float64) {
+	t.Helper()
 	tmp := make([]float64, len(x))
 	for i, v := range x {
 		tmp[i] = -e.LogProb(v)
@@ -110,7 +137,10 @@ func checkEntropy(t *testing.T, i int, x []float64, e entropyer, tol float64) {
 	}
 }
 
-func checkExKurtosis(t *testing.T, i int, x []float64, e exKurtosiser, tol float64) {
+// CheckExKurtosis checks the excess kurtosis of e against that of the
+// samples x.
+func CheckExKurtosis(t testing.TB, i int, x []float64, e ExKurtosiser, tol float64) {
+	t.Helper()
 	mean := e.Mean()
 	tmp := make([]float64, len(x))
 	for i, x := range x {
@@ -124,7 +154,9 @@ func checkExKurtosis(t *testing.T, i int, x []float64, e exKurtosiser, tol float
 	}
 }
 
-func checkSkewness(t *testing.T, i int, x []float64, s skewnesser, tol float64) {
+// CheckSkewness checks the skewness of s against that of the samples x.
+func CheckSkewness(t testing.TB, i int, x []float64, s Skewnesser, tol float64) {
+	t.Helper()
 	mean := s.Mean()
 	std := s.StdDev()
 	tmp := make([]float64, len(x))
@@ -138,7 +170,12 @@ func checkSkewness(t *testing.T, i int, x []float64, s skewnesser, tol float64)
 	}
 }
 
-func checkCDFSurvival(t *testing.T, i int, xs []float64, c cdfer, tol float64) {
+// CheckCDFSurvival checks the CDF of c against the empirical CDF of the
+// sorted samples xs at each unique sample, and that the survival function is
+// its complement. It is intended for discrete distributions, with few unique
+// samples.
+func CheckCDFSurvival(t testing.TB, i int, xs []float64, c CDFer, tol float64) {
+	t.Helper()
 	// CDF and survival check.
 	m := make(map[float64]int)
 	for _, v := range xs {
@@ -156,7 +193,11 @@ func checkCDFSurvival(t *testing.T, i int, xs []float64, c cdfer, tol float64) {
 	}
 }
 
-func checkQuantileCDFSurvival(t *testing.T, i int, xs []float64, c cumulanter, tol float64) {
+// CheckQuantileCDFSurvival checks the CDF of c against the empirical CDF of
+// the sorted samples xs and against the quantile function at a few
+// quantiles, and that the survival function is its complement.
+func CheckQuantileCDFSurvival(t testing.TB, i int, xs []float64, c Cumulanter, tol float64) {
+	t.Helper()
 	// Quantile, CDF, and survival check.
 	for i, p := range []float64{0.1, 0.25, 0.5, 0.75, 0.9} {
 		x := c.Quantile(p)
@@ -174,7 +215,10 @@ func checkQuantileCDFSurvival(t *testing.T, i int, xs []float64, c cumulanter, t
 	}
 }
 
-func checkProbContinuous(t *testing.T, i int, x []float64, p probLogprober, tol float64) {
+// CheckProbContinuous checks that the density of p integrates to 1, and
+// that Prob and LogProb agree at the samples x.
+func CheckProbContinuous(t testing.TB, i int, x []float64, p ProbLogProber, tol float64) {
+	t.Helper()
 	// Check that the PDF is consistent (integrates to 1).
 	q := quad.Fixed(p.Prob, math.Inf(-1), math.Inf(1), 1000000, nil, 0)
 	if math.Abs(q-1) > tol {
@@ -190,12 +234,13 @@ func checkProbContinuous(t *testing.T, i int, x []float64, p probLogprober, tol
 	}
 }
 
-// checkProbQuantContinuous checks that the Prob, Rand, and Quantile are all consistent.
-// checkProbContinuous only checks that Prob is a valid distribution (integrates
+// CheckProbQuantContinuous checks that the Prob, Rand, and Quantile are all consistent.
+// CheckProbContinuous only checks that Prob is a valid distribution (integrates
 // to 1 and greater than 0). However, this is also true if the PDF of a different
 // distribution is used. This checks that PDF is also consistent with the
 // CDF implementation and the random samples.
-func checkProbQuantContinuous(t *testing.T, i int, xs []float64, c cumulantProber, tol float64) {
+func CheckProbQuantContinuous(t testing.TB, i int, xs []float64, c CumulantProber, tol float64) {
+	t.Helper()
 	ps := make([]float64, 101)
 	floats.Span(ps, 0, 1)
 
@@ -233,8 +278,9 @@ func checkProbQuantContinuous(t *testing.T, i int, xs []float64, c cumulantProbe
 	}
 }
 
-// checkProbDiscrete confirms that PDF and Rand are consistent for discrete distributions.
-func checkProbDiscrete(t *testing.T, i int, xs []float64, p probLogprober, tol float64) {
+// CheckProbDiscrete confirms that PDF and Rand are consistent for discrete distributions.
+func CheckProbDiscrete(t testing.TB, i int, xs []float64, p ProbLogProber, tol float64) {
+	t.Helper()
 	// Make a map of all of the unique samples.
 	m := make(map[float64]int)
 	for _, v := range xs {
@@ -251,9 +297,12 @@ func checkProbDiscrete(t *testing.T, i int, xs []float64, p probLogprober, tol f
 	}
 }
 
-// testRandLogProb tests that LogProb and Rand give consistent results. This
-// can be used when the distribution does not implement CDF.
-func testRandLogProbContinuous(t *testing.T, i int, min float64, x []float64, f distuv.LogProber, tol float64, bins int) {
+// CheckRandLogProbContinuous checks that LogProb and Rand give consistent
+// results, integrating the density from min, the lower bound of the support,
+// to each of bins-1 evenly spaced empirical quantiles of the sorted samples x.
+// This can be used when the distribution does not implement CDF.
+func CheckRandLogProbContinuous(t testing.TB, i int, min float64, x []float64, f distuv.LogProber, tol float64, bins int) {
+	t.Helper()
 	for cdf := 1 / float64(bins); cdf <= 1-1/float64(bins); cdf += 1 / float64(bins) {
 		// Get the estimated CDF from the samples
 		pt := stat.Quantile(cdf, stat.Empirical, x, nil)
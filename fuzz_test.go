@@ -0,0 +1,157 @@
+//go:build go1.18
+// +build go1.18
+
+package statext
+
+import (
+	"math"
+	"testing"
+)
+
+// fuzzShape reports whether v is a shape parameter the fuzz targets check,
+// positive and within the range where the special functions are accurate.
+func fuzzShape(v float64) bool {
+	return v >= 1e-2 && v <= 1e4
+}
+
+func FuzzBetaPrime(f *testing.F) {
+	f.Add(2.0, 3.0, 0.5)
+	f.Add(0.5, 0.5, 1e-8)
+	f.Add(12.0, 16.0, 100.0)
+	f.Add(1.0, 1.0, -1.0)
+	f.Fuzz(func(t *testing.T, alpha, beta, x float64) {
+		if !fuzzShape(alpha) || !fuzzShape(beta) || math.IsNaN(x) {
+			t.Skip()
+		}
+		b := BetaPrime{Alpha: alpha, Beta: beta}
+		if lp := b.LogProb(x); math.IsNaN(lp) {
+			t.Errorf("LogProb(%v) is NaN for %+v", x, b)
+		}
+		if p := b.Prob(x); !(p >= 0) {
+			t.Errorf("Prob(%v) = %v for %+v", x, p, b)
+		}
+		cdf, surv := b.CDF(x), b.Survival(x)
+		if !(cdf >= 0 && cdf <= 1) || !(surv >= 0 && surv <= 1) {
+			t.Fatalf("CDF(%v) = %v, Survival = %v out of [0, 1] for %+v", x, cdf, surv, b)
+		}
+		if math.Abs(cdf+surv-1) > 1e-14 {
+			t.Errorf("CDF(%v) + Survival = %v for %+v", x, cdf+surv, b)
+		}
+		// The CDF is non-decreasing.
+		for _, y := range []float64{x + math.Abs(x)*1e-3 + 1e-300, 2*math.Abs(x) + 1} {
+			if c := b.CDF(y); c < cdf {
+				t.Errorf("CDF decreasing from %v at %v to %v at %v for %+v", cdf, x, c, y, b)
+			}
+		}
+		// The quantile of the CDF returns the CDF, within the accuracy of the
+		// inverse incomplete beta function.
+		if cdf > 1e-6 && cdf < 1-1e-6 {
+			if c := b.CDF(b.Quantile(cdf)); math.Abs(c-cdf) > 1e-6 {
+				t.Errorf("CDF(Quantile(%v)) = %v for %+v", cdf, c, b)
+			}
+		}
+	})
+}
+
+func FuzzBetaBinomial(f *testing.F) {
+	f.Add(uint16(20), 12.0, 16.0)
+	f.Add(uint16(1), 0.5, 0.5)
+	f.Add(uint16(500), 0.1, 30.0)
+	f.Fuzz(func(t *testing.T, n uint16, alpha, beta float64) {
+		if n == 0 || n > 1000 || !fuzzShape(alpha) || !fuzzShape(beta) {
+			t.Skip()
+		}
+		b := BetaBinomial{N: float64(n), Alpha: alpha, Beta: beta}
+		var sum, prev float64
+		for k := 0.0; k <= b.N; k++ {
+			p := b.Prob(k)
+			if !(p >= 0 && p <= 1) {
+				t.Fatalf("Prob(%v) = %v for %+v", k, p, b)
+			}
+			sum += p
+			cdf := b.CDF(k)
+			if !(cdf >= 0 && cdf <= 1) {
+				t.Fatalf("CDF(%v) = %v out of [0, 1] for %+v", k, cdf, b)
+			}
+			if cdf < prev-1e-15 {
+				t.Errorf("CDF decreasing from %v to %v at %v for %+v", prev, cdf, k, b)
+			}
+			if math.Abs(cdf+b.Survival(k)-1) > 1e-12 {
+				t.Errorf("CDF(%v) + Survival = %v for %+v", k, cdf+b.Survival(k), b)
+			}
+			prev = cdf
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("Probabilities sum to %v for %+v", sum, b)
+		}
+	})
+}
+
+func FuzzPoissonBinomial(f *testing.F) {
+	f.Add([]byte{153, 25, 204})
+	f.Add([]byte{0, 255, 128, 1, 254})
+	f.Add([]byte("the quick brown fox jumps over the lazy dog"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 || len(data) > 512 {
+			t.Skip()
+		}
+		probs := make([]float64, len(data))
+		for i, v := range data {
+			probs[i] = float64(v) / 255
+		}
+		p := NewPoissonBinomial(probs, nil)
+		var sum, prev float64
+		for k := 0.0; k <= float64(len(probs)); k++ {
+			pk := p.Prob(k)
+			if !(pk >= 0 && pk <= 1+1e-12) { // FFT rounding can exceed 1
+				t.Fatalf("Prob(%v) = %v for %v", k, pk, probs)
+			}
+			sum += pk
+			cdf := p.CDF(k)
+			if !(cdf >= 0 && cdf <= 1) {
+				t.Fatalf("CDF(%v) = %v out of [0, 1] for %v", k, cdf, probs)
+			}
+			if cdf < prev-1e-15 {
+				t.Errorf("CDF decreasing from %v to %v at %v for %v", prev, cdf, k, probs)
+			}
+			if math.Abs(cdf+p.Survival(k)-1) > 1e-12 {
+				t.Errorf("CDF(%v) + Survival = %v for %v", k, cdf+p.Survival(k), probs)
+			}
+			prev = cdf
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("Probabilities sum to %v for %v", sum, probs)
+		}
+	})
+}
+
+func FuzzDirichletWinner(f *testing.F) {
+	f.Add([]byte{128, 144, 160})
+	f.Add([]byte{0, 255})
+	f.Add([]byte{100, 100, 100, 100, 101})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 || len(data) > 8 {
+			t.Skip()
+		}
+		// Alphas from 1e-2 to 1e4 on a log scale.
+		alphas := make([]float64, len(data))
+		for i, v := range data {
+			alphas[i] = math.Pow(10, -2+6*float64(v)/255)
+		}
+		const tol = 1e-8
+		probs, err := DirichletWinnerE(alphas, tol)
+		if err != nil {
+			t.Fatalf("DirichletWinnerE(%v) error: %v", alphas, err)
+		}
+		var sum float64
+		for _, p := range probs {
+			if !(p >= 0 && p <= 1) {
+				t.Errorf("Probability %v out of [0, 1] for %v", p, alphas)
+			}
+			sum += p
+		}
+		if math.Abs(sum-1) > float64(2*len(alphas))*tol {
+			t.Errorf("Probabilities sum to %v for %v", sum, alphas)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package statext
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/argusdusty/statext/internal/reference"
+	"golang.org/x/exp/rand"
+)
+
+// update regenerates the golden tables in testdata/golden from the exact
+// reference implementations, with
+//  go test -run Golden -update
+var update = flag.Bool("update", false, "update the golden tables")
+
+// goldenFile reads the golden table name into v, first writing it from
+// generate if the tables are being updated.
+func goldenFile(t *testing.T, name string, v interface{}, generate func() interface{}) {
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		b, err := json.MarshalIndent(generate(), "", "\t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goldenMismatch reports whether got differs from the exact value want by
+// more than absTol + relTol*want.
+func goldenMismatch(got, want, absTol, relTol float64) bool {
+	return !(math.Abs(got-want) <= absTol+relTol*math.Abs(want))
+}
+
+// eps is the machine epsilon of float64.
+const eps = 2.220446049250313e-16
+
+type poissonBinomialGolden struct {
+	P   []float64
+	Pmf []float64
+}
+
+func TestPoissonBinomialGolden(t *testing.T) {
+	var cases []poissonBinomialGolden
+	goldenFile(t, "poissonbinomial", &cases, func() interface{} {
+		rnd := rand.New(rand.NewSource(1))
+		var cases []poissonBinomialGolden
+		for _, test := range []struct {
+			n     int
+			scale float64
+		}{
+			{5, 1}, {20, 1}, {64, 1}, {200, 1}, {100, 0.01}, {300, 0.2},
+		} {
+			p := make([]float64, test.n)
+			rats := make([]*big.Rat, test.n)
+			for i := range p {
+				p[i] = rnd.Float64() * test.scale
+				rats[i] = new(big.Rat).SetFloat64(p[i])
+			}
+			cases = append(cases, poissonBinomialGolden{p, reference.Float64s(reference.PoissonBinomialPmf(rats))})
+		}
+		return cases
+	})
+	for i, c := range cases {
+		p := NewPoissonBinomial(c.P, nil)
+		// The FFT leaves an absolute error of up to about sqrt(n)/2 machine
+		// epsilons on every probability, which is its accuracy limit in the
+		// tails, where relative errors reach 1e-3 at probabilities near 1e-12
+		// for n = 300. Near the mode, the relative error is up to about 2n
+		// machine epsilons. Check within twice these limits, so that any loss
+		// of accuracy in the tails is caught.
+		n := float64(len(c.P))
+		for k, want := range c.Pmf {
+			if got := p.Prob(float64(k)); goldenMismatch(got, want, math.Sqrt(n)*eps, 4*n*eps) {
+				t.Errorf("PoissonBinomial pmf mismatch. Case %v, k = %v: got %v want %v", i, k, got, want)
+			}
+		}
+	}
+}
+
+type betaBinomialGolden struct {
+	N           int
+	Alpha, Beta [2]int64 // Numerator and denominator
+	Pmf         []float64
+}
+
+func TestBetaBinomialGolden(t *testing.T) {
+	var cases []betaBinomialGolden
+	goldenFile(t, "betabinomial", &cases, func() interface{} {
+		var cases []betaBinomialGolden
+		for _, c := range []betaBinomialGolden{
+			{N: 20, Alpha: [2]int64{12, 1}, Beta: [2]int64{16, 1}},
+			{N: 34, Alpha: [2]int64{49, 1}, Beta: [2]int64{34, 1}},
+			{N: 50, Alpha: [2]int64{1, 2}, Beta: [2]int64{1, 2}},
+			{N: 100, Alpha: [2]int64{1, 10}, Beta: [2]int64{301, 10}},
+			{N: 200, Alpha: [2]int64{300, 1}, Beta: [2]int64{7, 4}},
+		} {
+			alpha, beta := big.NewRat(c.Alpha[0], c.Alpha[1]), big.NewRat(c.Beta[0], c.Beta[1])
+			c.Pmf = reference.Float64s(reference.BetaBinomialPmf(c.N, alpha, beta))
+			cases = append(cases, c)
+		}
+		return cases
+	})
+	for i, c := range cases {
+		b := BetaBinomial{
+			N:     float64(c.N),
+			Alpha: float64(c.Alpha[0]) / float64(c.Alpha[1]),
+			Beta:  float64(c.Beta[0]) / float64(c.Beta[1]),
+		}
+		for k, want := range c.Pmf {
+			if got := b.Prob(float64(k)); goldenMismatch(got, want, 0, 1e-10) {
+				t.Errorf("BetaBinomial pmf mismatch. Case %v, k = %v: got %v want %v", i, k, got, want)
+			}
+		}
+	}
+}
+
+type betaPrimeGolden struct {
+	Alpha, Beta int
+	X           []float64
+	CDF         []float64
+	Survival    []float64
+}
+
+func TestBetaPrimeGolden(t *testing.T) {
+	var cases []betaPrimeGolden
+	goldenFile(t, "betaprime", &cases, func() interface{} {
+		var cases []betaPrimeGolden
+		for _, c := range []betaPrimeGolden{
+			{Alpha: 1, Beta: 1},
+			{Alpha: 2, Beta: 3},
+			{Alpha: 12, Beta: 16},
+			{Alpha: 49, Beta: 34},
+			{Alpha: 200, Beta: 3},
+			{Alpha: 2, Beta: 150},
+		} {
+			// Out to x = 1e20, where the Survival of the small Beta cases
+			// is tiny but nonzero, and 1 - CDF would underflow to 0.
+			for e := -20.0; e <= 20; e += 0.5 {
+				x := math.Pow(10, e)
+				cdf := reference.BetaPrimeCDF(c.Alpha, c.Beta, new(big.Rat).SetFloat64(x))
+				survival := new(big.Rat).Sub(big.NewRat(1, 1), cdf)
+				c.X = append(c.X, x)
+				c.CDF = append(c.CDF, reference.Float64s([]*big.Rat{cdf})[0])
+				c.Survival = append(c.Survival, reference.Float64s([]*big.Rat{survival})[0])
+			}
+			cases = append(cases, c)
+		}
+		return cases
+	})
+	for i, c := range cases {
+		b := BetaPrime{Alpha: float64(c.Alpha), Beta: float64(c.Beta)}
+		for j, x := range c.X {
+			// Each tail is computed directly, so keeps its relative accuracy
+			// down to the subnormals.
+			if got, want := b.CDF(x), c.CDF[j]; goldenMismatch(got, want, 1e-300, 1e-10) {
+				t.Errorf("BetaPrime CDF mismatch. Case %v, x = %v: got %v want %v", i, x, got, want)
+			}
+			if got, want := b.Survival(x), c.Survival[j]; goldenMismatch(got, want, 1e-300, 1e-10) {
+				t.Errorf("BetaPrime Survival mismatch. Case %v, x = %v: got %v want %v", i, x, got, want)
+			}
+		}
+	}
+}
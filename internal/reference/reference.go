@@ -0,0 +1,114 @@
+// Package reference provides exact and high-precision implementations of
+// the distributions of statext, using math/big, as oracles for tests.
+// They are far too slow for anything else.
+package reference
+
+import (
+	"math/big"
+)
+
+// PoissonBinomialPmf returns the exact pmf of the Poisson binomial
+// distribution with success probabilities p, by direct convolution of the
+// Bernoulli pmfs. Entry k is the probability of exactly k successes.
+func PoissonBinomialPmf(p []*big.Rat) []*big.Rat {
+	pmf := make([]*big.Rat, len(p)+1)
+	pmf[0] = big.NewRat(1, 1)
+	for i := 1; i < len(pmf); i++ {
+		pmf[i] = new(big.Rat)
+	}
+	one := big.NewRat(1, 1)
+	q := new(big.Rat)
+	t := new(big.Rat)
+	for n, prob := range p {
+		q.Sub(one, prob)
+		// pmf[k] = (1-prob)*pmf[k] + prob*pmf[k-1], from the top down so
+		// that pmf[k-1] is still that of the first n probabilities.
+		for k := n + 1; k > 0; k-- {
+			t.Mul(prob, pmf[k-1])
+			pmf[k].Mul(pmf[k], q)
+			pmf[k].Add(pmf[k], t)
+		}
+		pmf[0].Mul(pmf[0], q)
+	}
+	return pmf
+}
+
+// BetaBinomialPmf returns the exact pmf of the beta-binomial distribution
+// with n trials and rational shape parameters alpha and beta, using
+//  f(k) = (n choose k) * product(alpha+i, i < k) * product(beta+j, j < n-k)
+//         / product(alpha+beta+m, m < n)
+func BetaBinomialPmf(n int, alpha, beta *big.Rat) []*big.Rat {
+	// rising[k] = product(a+i, i < k), the rising factorial.
+	rising := func(a *big.Rat) []*big.Rat {
+		r := make([]*big.Rat, n+1)
+		r[0] = big.NewRat(1, 1)
+		t := new(big.Rat)
+		for i := 1; i <= n; i++ {
+			t.SetInt64(int64(i - 1))
+			t.Add(t, a)
+			r[i] = new(big.Rat).Mul(r[i-1], t)
+		}
+		return r
+	}
+	ra, rb := rising(alpha), rising(beta)
+	norm := rising(new(big.Rat).Add(alpha, beta))[n]
+	pmf := make([]*big.Rat, n+1)
+	choose := new(big.Int)
+	for k := 0; k <= n; k++ {
+		choose.Binomial(int64(n), int64(k))
+		pmf[k] = new(big.Rat).SetInt(choose)
+		pmf[k].Mul(pmf[k], ra[k])
+		pmf[k].Mul(pmf[k], rb[n-k])
+		pmf[k].Quo(pmf[k], norm)
+	}
+	return pmf
+}
+
+// BetaPrimeCDF returns the exact CDF of the Beta prime distribution with
+// positive integer shape parameters alpha and beta at the rational x >= 0.
+// With z = x/(1+x), the CDF is the regularized incomplete beta function
+// I_z(alpha, beta), which for integer shapes is the binomial tail
+//  sum((alpha+beta-1 choose j) * z^j * (1-z)^(alpha+beta-1-j), j >= alpha)
+// The survival function is 1 minus the result, exactly.
+// BetaPrimeCDF panics if alpha or beta is less than 1, or x is negative.
+func BetaPrimeCDF(alpha, beta int, x *big.Rat) *big.Rat {
+	if alpha < 1 || beta < 1 {
+		panic("reference: beta prime shapes must be positive integers")
+	}
+	if x.Sign() < 0 {
+		panic("reference: beta prime x negative")
+	}
+	one := big.NewRat(1, 1)
+	den := new(big.Rat).Add(one, x)
+	z := new(big.Rat).Quo(x, den)
+	w := new(big.Rat).Quo(one, den) // 1 - z
+	m := alpha + beta - 1
+	// Powers of z and w.
+	zp := make([]*big.Rat, m+1)
+	wp := make([]*big.Rat, m+1)
+	zp[0], wp[0] = big.NewRat(1, 1), big.NewRat(1, 1)
+	for j := 1; j <= m; j++ {
+		zp[j] = new(big.Rat).Mul(zp[j-1], z)
+		wp[j] = new(big.Rat).Mul(wp[j-1], w)
+	}
+	cdf := new(big.Rat)
+	choose := new(big.Int)
+	t := new(big.Rat)
+	for j := alpha; j <= m; j++ {
+		choose.Binomial(int64(m), int64(j))
+		t.SetInt(choose)
+		t.Mul(t, zp[j])
+		t.Mul(t, wp[m-j])
+		cdf.Add(cdf, t)
+	}
+	return cdf
+}
+
+// Float64s returns the nearest float64 to each of rats.
+func Float64s(rats []*big.Rat) []float64 {
+	f := make([]float64, len(rats))
+	for i, r := range rats {
+		f[i], _ = r.Float64()
+	}
+	return f
+}
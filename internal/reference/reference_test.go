@@ -0,0 +1,65 @@
+package reference
+
+import (
+	"math/big"
+	"testing"
+)
+
+func sum(rats []*big.Rat) *big.Rat {
+	s := new(big.Rat)
+	for _, r := range rats {
+		s.Add(s, r)
+	}
+	return s
+}
+
+func TestPoissonBinomialPmf(t *testing.T) {
+	// With equal probabilities, the pmf is binomial.
+	const n = 10
+	p := make([]*big.Rat, n)
+	for i := range p {
+		p[i] = big.NewRat(1, 2)
+	}
+	pmf := PoissonBinomialPmf(p)
+	for k, v := range pmf {
+		want := new(big.Rat).SetFrac(new(big.Int).Binomial(n, int64(k)), big.NewInt(1<<n))
+		if v.Cmp(want) != 0 {
+			t.Errorf("Binomial pmf mismatch at %v: got %v want %v", k, v, want)
+		}
+	}
+	p = []*big.Rat{big.NewRat(1, 3), big.NewRat(1, 10), big.NewRat(4, 5), big.NewRat(0, 1)}
+	if s := sum(PoissonBinomialPmf(p)); s.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("Poisson binomial pmf sums to %v", s)
+	}
+}
+
+func TestBetaBinomialPmf(t *testing.T) {
+	// With alpha = beta = 1, the pmf is uniform.
+	const n = 12
+	one := big.NewRat(1, 1)
+	for k, v := range BetaBinomialPmf(n, one, one) {
+		if v.Cmp(big.NewRat(1, n+1)) != 0 {
+			t.Errorf("Uniform pmf mismatch at %v: got %v", k, v)
+		}
+	}
+	if s := sum(BetaBinomialPmf(30, big.NewRat(1, 2), big.NewRat(49, 10))); s.Cmp(one) != 0 {
+		t.Errorf("Beta-binomial pmf sums to %v", s)
+	}
+}
+
+func TestBetaPrimeCDF(t *testing.T) {
+	// With alpha = beta = 1, the CDF is x/(1+x).
+	for _, x := range []*big.Rat{big.NewRat(0, 1), big.NewRat(1, 3), big.NewRat(7, 1)} {
+		want := new(big.Rat).Quo(x, new(big.Rat).Add(x, big.NewRat(1, 1)))
+		if got := BetaPrimeCDF(1, 1, x); got.Cmp(want) != 0 {
+			t.Errorf("BetaPrimeCDF(1, 1, %v) = %v want %v", x, got, want)
+		}
+	}
+	// With alpha = 1, the CDF is 1 - (1+x)^-beta.
+	x := big.NewRat(3, 2)
+	want := new(big.Rat).SetFrac64(2*2*2, 5*5*5)
+	want.Sub(big.NewRat(1, 1), want)
+	if got := BetaPrimeCDF(1, 3, x); got.Cmp(want) != 0 {
+		t.Errorf("BetaPrimeCDF(1, 3, %v) = %v want %v", x, got, want)
+	}
+}
@@ -11,6 +11,9 @@ import (
 // independent Bernoulli trials that are not necessarily identically distributed.
 // The value of entries in P must be between 0 and 1.
 // More information at https://en.wikipedia.org/wiki/Poisson_binomial_distribution.
+// The probabilities are computed with an FFT, so each carries an absolute
+// error of up to around sqrt(len(p)) machine epsilons, and probabilities far
+// in the tails have correspondingly large relative errors.
 type PoissonBinomial struct {
 	p   []float64
 	dim int
@@ -85,6 +88,12 @@ func (p PoissonBinomial) computePmf() []float64 {
 		panic(err)
 	}
 	pmf := gofft.Complex128ToFloat64Array(data[:N])
+	for i, v := range pmf {
+		// FFT rounding errors can leave tiny negative probabilities
+		if v < 0 {
+			pmf[i] = 0
+		}
+	}
 	return pmf
 }
 
@@ -93,7 +102,7 @@ func (p PoissonBinomial) computeCdf() []float64 {
 	var t float64
 	for i := 0; i < len(p.pmf); i++ {
 		t += p.pmf[i]
-		cdf[i] = t
+		cdf[i] = math.Min(t, 1)
 	}
 	return cdf
 }
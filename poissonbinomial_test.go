@@ -1,6 +1,7 @@
 package statext
 
 import (
+	"github.com/argusdusty/statext/disttest"
 	"golang.org/x/exp/rand"
 	"math"
 	"sort"
@@ -169,10 +170,11 @@ func TestPoissonBinomialCDF(t *testing.T) {
 }
 
 func TestPoissonBinomial(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
 	// Check some specific cases
 	for i, p := range []PoissonBinomial{
-		NewPoissonBinomial([]float64{0.6, 0.1, 0.8}, nil),
-		NewPoissonBinomial([]float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}, nil),
+		NewPoissonBinomial([]float64{0.6, 0.1, 0.8}, src),
+		NewPoissonBinomial([]float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}, src),
 	} {
 		if len(p.p) != p.NumParameters() {
 			t.Errorf("NumParameters mismatch. Case %v. Got %v, want %v", i, p.NumParameters(), len(p.p))
@@ -190,15 +192,16 @@ func testPoissonBinomial(t *testing.T, p PoissonBinomial, i int) {
 		n   = 2e6
 	)
 	x := make([]float64, n)
-	generateSamples(x, p)
+	disttest.GenerateSamples(x, p)
 	sort.Float64s(x)
 
-	checkMean(t, i, x, p, tol)
-	checkSkewness(t, i, x, p, tol)
-	checkVarAndStd(t, i, x, p, tol)
-	checkExKurtosis(t, i, x, p, tol)
-	checkProbDiscrete(t, i, x, p, tol)
-	checkCDFSurvival(t, i, x, p, tol)
+	disttest.CheckMean(t, i, x, p, tol)
+	disttest.CheckSkewness(t, i, x, p, tol)
+	disttest.CheckVarAndStd(t, i, x, p, tol)
+	disttest.CheckExKurtosis(t, i, x, p, tol)
+	disttest.CheckRandLogProbDiscrete(t, i, x, p, 1e-3)
+	disttest.CheckProbDiscrete(t, i, x, p, tol)
+	disttest.CheckCDFSurvival(t, i, x, p, tol)
 }
 
 func randProbs(n int) []float64 {
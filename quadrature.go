@@ -0,0 +1,258 @@
+package statext
+
+import (
+	"container/heap"
+	"math"
+)
+
+// QuadMethod selects the numerical integration method used by
+// DirichletWinnerMethod.
+type QuadMethod int
+
+const (
+	// AdaptiveSimpson is the recursive adaptive Simpson integration used by
+	// DirichletWinner, restarting with an increasing minimum depth until the
+	// probabilities sum to 1 within the tolerance.
+	AdaptiveSimpson QuadMethod = iota
+	// GaussKronrod is globally adaptive Gauss-Kronrod integration with the
+	// 7-point Gauss and 15-point Kronrod rules, which repeatedly bisects the
+	// subinterval with the largest error estimate, keeping the others.
+	GaussKronrod
+	// TanhSinh is double-exponential (tanh-sinh) integration, which halves
+	// its step on each level, reusing the nodes of the previous levels.
+	TanhSinh
+)
+
+// quadResult holds the result of integrating a vector-valued function over
+// [0, 1] with gaussKronrodQuad or tanhSinhQuad.
+type quadResult struct {
+	result    []float64 // Integral estimates
+	errs      []float64 // Estimated absolute error of each integral
+	evals     int       // Number of evaluations of f
+	depth     int       // Deepest bisection, or finest level reached
+	converged bool      // Whether every error estimate is within the tolerance
+}
+
+// Abscissae and weights of the 15-point Kronrod rule on [-1, 1], for the
+// nodes ±gkNodes[i], and the weights of the embedded 7-point Gauss rule,
+// for the nodes ±gkNodes[1], ±gkNodes[3], ±gkNodes[5] and 0.
+// From QUADPACK's qk15.
+var (
+	gkNodes = [8]float64{
+		0.991455371120812639206854697526329,
+		0.949107912342758524526189684047851,
+		0.864864423359769072789712788640926,
+		0.741531185599394439863864773280788,
+		0.586087235467691130294144845693013,
+		0.405845151377397166906606412076961,
+		0.207784955007898467600689403773245,
+		0,
+	}
+	kronrodWeights = [8]float64{
+		0.022935322010529224963732008058970,
+		0.063092092629978553290700663189204,
+		0.104790010322250183839876322541518,
+		0.140653259715525918745189590510238,
+		0.169004726639267902826583426598550,
+		0.190350578064785409913256402421014,
+		0.204432940075298892414161999234649,
+		0.209482141084727828012999174891714,
+	}
+	gaussWeights = [4]float64{
+		0.129484966168869693270611432679082,
+		0.279705391489276667901467771423780,
+		0.381830050505118944950369775488975,
+		0.417959183673469387755102040816327,
+	}
+)
+
+// gkInterval is a subinterval [a, b] of a Gauss-Kronrod integration, at
+// bisection depth depth, with its integral estimates and error estimates.
+type gkInterval struct {
+	a, b   float64
+	depth  int
+	result []float64
+	errs   []float64
+	maxErr float64
+}
+
+// gkHeap is a max-heap of gkIntervals by maxErr.
+type gkHeap []*gkInterval
+
+func (h gkHeap) Len() int            { return len(h) }
+func (h gkHeap) Less(i, j int) bool  { return h[i].maxErr > h[j].maxErr }
+func (h gkHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *gkHeap) Push(x interface{}) { *h = append(*h, x.(*gkInterval)) }
+func (h *gkHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// gaussKronrodQuad integrates the n-dimensional f over [0, 1] with globally
+// adaptive G7K15 quadrature, until the summed error estimates of every
+// component are within tol, or the subinterval with the largest error
+// reaches maxDepth.
+func gaussKronrodQuad(f func(y float64, dst []float64), n int, tol float64) quadResult {
+	res := quadResult{
+		result: make([]float64, n),
+		errs:   make([]float64, n),
+	}
+	fv := make([]float64, 15*n)
+	eval := func(a, b float64, depth int) *gkInterval {
+		iv := &gkInterval{a: a, b: b, depth: depth, result: make([]float64, n), errs: make([]float64, n)}
+		c, hl := (a+b)/2, (b-a)/2
+		for k, x := range gkNodes[:7] {
+			f(c-hl*x, fv[2*k*n:(2*k+1)*n])
+			f(c+hl*x, fv[(2*k+1)*n:(2*k+2)*n])
+		}
+		f(c, fv[14*n:])
+		res.evals += 15
+		for i := 0; i < n; i++ {
+			// Error estimate as in QUADPACK's qk15.
+			resk := kronrodWeights[7] * fv[14*n+i]
+			resg := gaussWeights[3] * fv[14*n+i]
+			resabs := math.Abs(resk)
+			for k := 0; k < 7; k++ {
+				s := fv[2*k*n+i] + fv[(2*k+1)*n+i]
+				resk += kronrodWeights[k] * s
+				resabs += kronrodWeights[k] * (math.Abs(fv[2*k*n+i]) + math.Abs(fv[(2*k+1)*n+i]))
+				if k%2 == 1 {
+					resg += gaussWeights[k/2] * s
+				}
+			}
+			mean := resk / 2
+			resasc := kronrodWeights[7] * math.Abs(fv[14*n+i]-mean)
+			for k := 0; k < 7; k++ {
+				resasc += kronrodWeights[k] * (math.Abs(fv[2*k*n+i]-mean) + math.Abs(fv[(2*k+1)*n+i]-mean))
+			}
+			err := math.Abs((resk - resg) * hl)
+			resasc *= hl
+			if resasc != 0 && err != 0 {
+				err = resasc * math.Min(1, math.Pow(200*err/resasc, 1.5))
+			}
+			err = math.Max(err, 50*epsilon*resabs*hl)
+			iv.result[i] = resk * hl
+			iv.errs[i] = err
+			iv.maxErr = math.Max(iv.maxErr, err)
+		}
+		return iv
+	}
+
+	// Start from the same 1<<minDepth subintervals as the Simpson integration.
+	h := &gkHeap{}
+	k := 1 << minDepth
+	for i := 0; i < k; i++ {
+		iv := eval(float64(i)/float64(k), float64(i+1)/float64(k), minDepth)
+		heap.Push(h, iv)
+		for j := range res.errs {
+			res.errs[j] += iv.errs[j]
+		}
+	}
+	for {
+		res.converged = true
+		for _, e := range res.errs {
+			if e > tol {
+				res.converged = false
+				break
+			}
+		}
+		top := (*h)[0]
+		if res.converged || top.depth >= maxDepth {
+			break
+		}
+		heap.Pop(h)
+		mid := (top.a + top.b) / 2
+		left, right := eval(top.a, mid, top.depth+1), eval(mid, top.b, top.depth+1)
+		heap.Push(h, left)
+		heap.Push(h, right)
+		for j := range res.errs {
+			res.errs[j] += left.errs[j] + right.errs[j] - top.errs[j]
+		}
+	}
+	// Sum afresh, rather than keep the running error totals.
+	for j := range res.errs {
+		res.errs[j] = 0
+	}
+	for _, iv := range *h {
+		for j := range res.result {
+			res.result[j] += iv.result[j]
+			res.errs[j] += iv.errs[j]
+		}
+		if iv.depth > res.depth {
+			res.depth = iv.depth
+		}
+	}
+	return res
+}
+
+// epsilon is the machine epsilon of float64.
+const epsilon = 1.0 / (1 << 52)
+
+const (
+	tanhSinhMax      = 4.0 // Truncate the tanh-sinh sum at |t| <= tanhSinhMax
+	tanhSinhMinLevel = 2   // Run at least this many levels
+	tanhSinhMaxLevel = 16  // Run at most this many levels
+)
+
+// tanhSinhQuad integrates the n-dimensional f over [0, 1] with tanh-sinh
+// quadrature, substituting y = 1/(1+exp(-π*sinh(t))) and applying the
+// trapezoidal rule in t with step 2^-level, until the change from the
+// previous level of every component is within tol.
+// At |t| = tanhSinhMax, y is within 1e-37 of the endpoints, so f is assumed
+// negligible beyond it.
+func tanhSinhQuad(f func(y float64, dst []float64), n int, tol float64) quadResult {
+	res := quadResult{
+		result: make([]float64, n),
+		errs:   make([]float64, n),
+	}
+	sum := make([]float64, n)
+	fx := make([]float64, n)
+	node := func(t float64) {
+		s := math.Pi * math.Sinh(t)
+		e := math.Exp(-math.Abs(s))
+		y := 1 / (1 + e)
+		if s < 0 {
+			y = e / (1 + e)
+		}
+		// dy/dt = y*(1-y)*π*cosh(t)
+		w := e / ((1 + e) * (1 + e)) * math.Pi * math.Cosh(t)
+		if w == 0 {
+			return
+		}
+		f(y, fx)
+		res.evals++
+		for i, v := range fx {
+			sum[i] += w * v
+		}
+	}
+	node(0)
+	for j := 1; float64(j) <= tanhSinhMax; j++ {
+		node(float64(j))
+		node(-float64(j))
+	}
+	copy(res.result, sum)
+	h := 1.0
+	for level := 1; level <= tanhSinhMaxLevel; level++ {
+		h /= 2
+		// Only the odd multiples of the new step are new nodes.
+		for j := 1; float64(j)*h <= tanhSinhMax; j += 2 {
+			node(float64(j) * h)
+			node(-float64(j) * h)
+		}
+		res.depth = level
+		res.converged = true
+		for i, v := range sum {
+			res.errs[i] = math.Abs(v*h - res.result[i])
+			res.result[i] = v * h
+			if res.errs[i] > tol {
+				res.converged = false
+			}
+		}
+		if res.converged && level >= tanhSinhMinLevel {
+			break
+		}
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package statext
+
+import (
+	"math"
+	"testing"
+)
+
+// quadTestFunc holds integrands over [0, 1] with known integrals, including
+// an endpoint singularity in the derivative and a narrow peak.
+func quadTestFunc(y float64, dst []float64) {
+	dst[0] = y * y
+	dst[1] = math.Exp(y)
+	dst[2] = math.Sqrt(y)
+	dst[3] = 1 / (1 + 2500*(y-0.3)*(y-0.3))
+}
+
+var quadTestIntegrals = []float64{1.0 / 3, math.E - 1, 2.0 / 3, (math.Atan(35) + math.Atan(15)) / 50}
+
+func TestGaussKronrodQuad(t *testing.T) {
+	for _, tol := range []float64{1e-3, 1e-8, 1e-13} {
+		r := gaussKronrodQuad(quadTestFunc, 4, tol)
+		if !r.converged || r.evals%15 != 0 || r.depth < minDepth {
+			t.Errorf("Gauss-Kronrod bad diagnostics. tol: %g converged: %v evaluations: %v depth: %v", tol, r.converged, r.evals, r.depth)
+		}
+		for i, want := range quadTestIntegrals {
+			err := math.Abs(r.result[i] - want)
+			if err > tol || err > r.errs[i]+1e-15 {
+				t.Errorf("Gauss-Kronrod mismatch. tol: %g integral: %v got: %v want: %v estimated error: %g", tol, i, r.result[i], want, r.errs[i])
+			}
+		}
+	}
+}
+
+func TestTanhSinhQuad(t *testing.T) {
+	for _, tol := range []float64{1e-3, 1e-8, 1e-13} {
+		r := tanhSinhQuad(quadTestFunc, 4, tol)
+		if !r.converged || r.depth < tanhSinhMinLevel {
+			t.Errorf("Tanh-sinh bad diagnostics. tol: %g converged: %v evaluations: %v level: %v", tol, r.converged, r.evals, r.depth)
+		}
+		for i, want := range quadTestIntegrals {
+			if err := math.Abs(r.result[i] - want); err > tol {
+				t.Errorf("Tanh-sinh mismatch. tol: %g integral: %v got: %v want: %v", tol, i, r.result[i], want)
+			}
+		}
+	}
+	// Each level reuses the nodes of the previous levels: level k adds
+	// the odd multiples of 2^-k up to tanhSinhMax on each side.
+	r := tanhSinhQuad(quadTestFunc, 4, 1e-13)
+	want := 1 + 2*int(tanhSinhMax)<<uint(r.depth)
+	if r.evals != want {
+		t.Errorf("Tanh-sinh evaluations mismatch. got: %v want: %v", r.evals, want)
+	}
+}
@@ -0,0 +1,47 @@
+package statext
+
+import (
+	"golang.org/x/exp/rand"
+)
+
+// RateRatio is the posterior distribution of the ratio λ1/λ2 of the rates of
+// two independent Poisson processes, given the counts of events observed
+// over their exposures and a common Gamma prior on the rates.
+//
+// Each rate has a Gamma(shape+count, rate+exposure) posterior, and the ratio
+// of independent Gamma variables with unit rate is BetaPrime distributed, so
+// the ratio of the rates is a scaled BetaPrime: a GeneralizedBetaPrime with
+// P = 1 and Q = (rate+exposure2)/(rate+exposure1).
+type RateRatio struct {
+	GeneralizedBetaPrime
+}
+
+// NewRateRatio returns the posterior distribution of λ1/λ2 given count1
+// events over exposure1 and count2 events over exposure2, with a
+// Gamma(priorShape, priorRate) prior on each rate, such as 0.5 and 0 for the
+// Jeffreys prior. Samples are drawn from src.
+// NewRateRatio panics if either posterior Gamma parameter is not positive.
+func NewRateRatio(count1, exposure1, count2, exposure2, priorShape, priorRate float64, src rand.Source) RateRatio {
+	a1, b1 := priorShape+count1, priorRate+exposure1
+	a2, b2 := priorShape+count2, priorRate+exposure2
+	if !(a1 > 0 && b1 > 0 && a2 > 0 && b2 > 0) {
+		panic("rate ratio: posterior parameter not positive")
+	}
+	return RateRatio{GeneralizedBetaPrime{Alpha: a1, Beta: a2, P: 1, Q: b2 / b1, Src: src}}
+}
+
+// ProbGreater returns the posterior probability that the ratio is greater
+// than x. ProbGreater(1) is the probability that λ1 > λ2.
+func (r RateRatio) ProbGreater(x float64) float64 {
+	return r.Survival(x)
+}
+
+// CredibleInterval returns the equal-tailed credible interval of the ratio
+// containing probability mass level.
+// CredibleInterval panics if level is not in [0, 1].
+func (r RateRatio) CredibleInterval(level float64) (lower, upper float64) {
+	if !(level >= 0 && level <= 1) {
+		panic("rate ratio: bad credible level")
+	}
+	return r.Quantile((1 - level) / 2), r.Quantile((1 + level) / 2)
+}
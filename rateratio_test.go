@@ -0,0 +1,56 @@
+package statext
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestRateRatio(t *testing.T) {
+	// Equal counts and exposures make the ratio symmetric about 1 on the
+	// log scale.
+	r := NewRateRatio(12, 3.5, 12, 3.5, 0.5, 0, nil)
+	if got := r.ProbGreater(1); math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("RateRatio symmetric mismatch: got %v want 0.5", got)
+	}
+	lower, upper := r.CredibleInterval(0.9)
+	if math.Abs(lower*upper-1) > 1e-10 {
+		t.Errorf("RateRatio symmetric interval mismatch: [%v, %v]", lower, upper)
+	}
+
+	// Compare with Monte Carlo samples of the ratio of the Gamma posteriors.
+	src := rand.New(rand.NewSource(1))
+	r = NewRateRatio(30, 2, 18, 1.5, 1, 0.1, src)
+	g1 := distuv.Gamma{Alpha: 31, Beta: 2.1, Src: src}
+	g2 := distuv.Gamma{Alpha: 19, Beta: 1.6, Src: src}
+	const samples = 100000
+	ratios := make([]float64, samples)
+	drawn := make([]float64, samples)
+	var greater float64
+	for k := range ratios {
+		ratios[k] = g1.Rand() / g2.Rand()
+		if ratios[k] > 1 {
+			greater++
+		}
+		drawn[k] = r.Rand()
+	}
+	if got := r.ProbGreater(1); math.Abs(got-greater/samples) > 0.005 {
+		t.Errorf("RateRatio ProbGreater mismatch: got %v simulated %v", got, greater/samples)
+	}
+	sort.Float64s(ratios)
+	sort.Float64s(drawn)
+	lower, upper = r.CredibleInterval(0.9)
+	for _, x := range [][]float64{ratios, drawn} {
+		below := float64(sort.SearchFloat64s(x, lower)) / samples
+		above := 1 - float64(sort.SearchFloat64s(x, upper))/samples
+		if math.Abs(below-0.05) > 0.004 || math.Abs(above-0.05) > 0.004 {
+			t.Errorf("RateRatio interval mismatch. interval: [%v, %v] tails: %v %v", lower, upper, below, above)
+		}
+	}
+	if want := 31 / 2.1 * 1.6 / 18; math.Abs(r.Mean()-want) > 1e-12 {
+		t.Errorf("RateRatio mean mismatch: got %v want %v", r.Mean(), want)
+	}
+}
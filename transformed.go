@@ -0,0 +1,102 @@
+package statext
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// TransformBase is a distribution that can be transformed by Transformed.
+type TransformBase interface {
+	CDF(x float64) float64
+	LogProb(x float64) float64
+	Quantile(p float64) float64
+	Rand() float64
+	Survival(x float64) float64
+}
+
+// Transformed implements the distribution of Forward(X), for X following the
+// Base distribution and Forward a strictly monotone differentiable bijection.
+//
+// Transformed has density function
+//  Base.Prob(Inverse(y)) / |Deriv(Inverse(y))|
+//
+// Inverse must map points outside the range of Forward to points outside the
+// support of Base, so that they have no probability. Where an exact form of
+// the transformed distribution exists, such as OddsOf, it should be preferred.
+type Transformed struct {
+	// Base is the distribution of X.
+	Base TransformBase
+	// Forward is the bijection, and Inverse its inverse.
+	Forward, Inverse func(x float64) float64
+	// Deriv is the derivative of Forward.
+	Deriv func(x float64) float64
+	// Decreasing is whether Forward is decreasing rather than increasing.
+	Decreasing bool
+}
+
+// CDF computes the value of the cumulative density function at x.
+func (t Transformed) CDF(x float64) float64 {
+	if t.Decreasing {
+		return t.Base.Survival(t.Inverse(x))
+	}
+	return t.Base.CDF(t.Inverse(x))
+}
+
+// LogProb computes the natural logarithm of the value of the probability
+// density function at x.
+func (t Transformed) LogProb(x float64) float64 {
+	y := t.Inverse(x)
+	lp := t.Base.LogProb(y)
+	if math.IsInf(lp, -1) {
+		return lp
+	}
+	return lp - math.Log(math.Abs(t.Deriv(y)))
+}
+
+// Prob computes the value of the probability density function at x.
+func (t Transformed) Prob(x float64) float64 {
+	return math.Exp(t.LogProb(x))
+}
+
+// Quantile returns the inverse of the cumulative distribution function.
+func (t Transformed) Quantile(p float64) float64 {
+	if t.Decreasing {
+		return t.Forward(t.Base.Quantile(1 - p))
+	}
+	return t.Forward(t.Base.Quantile(p))
+}
+
+// Rand returns a random sample drawn from the distribution.
+func (t Transformed) Rand() float64 {
+	return t.Forward(t.Base.Rand())
+}
+
+// Survival returns the survival function (complementary CDF) at x.
+func (t Transformed) Survival(x float64) float64 {
+	if t.Decreasing {
+		return t.Base.CDF(t.Inverse(x))
+	}
+	return t.Base.Survival(t.Inverse(x))
+}
+
+// OddsOf returns the distribution of the odds X/(1-X) for X following the
+// Beta distribution b, which is the BetaPrime distribution with the same
+// parameters.
+func OddsOf(b distuv.Beta) BetaPrime {
+	return BetaPrime{Alpha: b.Alpha, Beta: b.Beta, Src: b.Src}
+}
+
+// ProbabilityOf returns the distribution of X/(1+X) for X following the
+// BetaPrime distribution b, the probability with odds X, which is the Beta
+// distribution with the same parameters. It is the inverse of OddsOf.
+func ProbabilityOf(b BetaPrime) distuv.Beta {
+	return distuv.Beta{Alpha: b.Alpha, Beta: b.Beta, Src: b.Src}
+}
+
+// ReciprocalOf returns the distribution of 1/X for X following the BetaPrime
+// distribution b, which is the BetaPrime distribution with the parameters
+// swapped.
+func ReciprocalOf(b BetaPrime) BetaPrime {
+	return BetaPrime{Alpha: b.Beta, Beta: b.Alpha, Src: b.Src}
+}
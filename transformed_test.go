@@ -0,0 +1,84 @@
+package statext
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/argusdusty/statext/disttest"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestTransformed(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for i, test := range []struct {
+		trans Transformed
+		want  disttest.CumulantProber
+	}{
+		{
+			// The odds of a Beta distribution.
+			Transformed{
+				Base:    distuv.Beta{Alpha: 3, Beta: 5, Src: src},
+				Forward: func(x float64) float64 { return x / (1 - x) },
+				Inverse: func(y float64) float64 { return y / (1 + y) },
+				Deriv:   func(x float64) float64 { return 1 / ((1 - x) * (1 - x)) },
+			},
+			BetaPrime{Alpha: 3, Beta: 5},
+		},
+		{
+			// The reciprocal of a BetaPrime distribution is decreasing.
+			Transformed{
+				Base:       BetaPrime{Alpha: 12, Beta: 16, Src: src},
+				Forward:    func(x float64) float64 { return 1 / x },
+				Inverse:    func(y float64) float64 { return 1 / y },
+				Deriv:      func(x float64) float64 { return -1 / (x * x) },
+				Decreasing: true,
+			},
+			BetaPrime{Alpha: 16, Beta: 12},
+		},
+	} {
+		for _, p := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+			x := test.want.Quantile(p)
+			if got := test.trans.Quantile(p); !floats.EqualWithinAbsOrRel(got, x, 1e-10, 1e-10) {
+				t.Errorf("Transformed quantile mismatch case %v at %v: got %v want %v", i, p, got, x)
+			}
+			if got, want := test.trans.CDF(x), test.want.CDF(x); !floats.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+				t.Errorf("Transformed CDF mismatch case %v at %v: got %v want %v", i, x, got, want)
+			}
+			if got, want := test.trans.Survival(x), test.want.Survival(x); !floats.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+				t.Errorf("Transformed Survival mismatch case %v at %v: got %v want %v", i, x, got, want)
+			}
+			if got, want := test.trans.LogProb(x), test.want.LogProb(x); !floats.EqualWithinAbsOrRel(got, want, 1e-10, 1e-10) {
+				t.Errorf("Transformed LogProb mismatch case %v at %v: got %v want %v", i, x, got, want)
+			}
+		}
+		if got := test.trans.Prob(-1); got != 0 {
+			t.Errorf("Transformed Prob outside support case %v: got %v", i, got)
+		}
+		x := make([]float64, 1e5)
+		disttest.GenerateSamples(x, test.trans)
+		sort.Float64s(x)
+		disttest.CheckQuantileCDFSurvival(t, i, x, test.trans, 1e-2)
+	}
+}
+
+func TestOddsOf(t *testing.T) {
+	b := distuv.Beta{Alpha: 2.5, Beta: 4}
+	o := OddsOf(b)
+	if o.Alpha != 2.5 || o.Beta != 4 {
+		t.Errorf("OddsOf mismatch: got %+v", o)
+	}
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.8} {
+		if got, want := o.CDF(x/(1-x)), b.CDF(x); math.Abs(got-want) > 1e-14 {
+			t.Errorf("OddsOf CDF mismatch at %v: got %v want %v", x, got, want)
+		}
+	}
+	if p := ProbabilityOf(o); p.Alpha != b.Alpha || p.Beta != b.Beta {
+		t.Errorf("ProbabilityOf mismatch: got %+v want %+v", p, b)
+	}
+	if r := ReciprocalOf(o); r.CDF(2) != o.Survival(0.5) {
+		t.Errorf("ReciprocalOf mismatch: got %v want %v", r.CDF(2), o.Survival(0.5))
+	}
+}
@@ -0,0 +1,238 @@
+package statext
+
+import (
+	"math"
+)
+
+// ProbCDFer is a distribution with a probability density (or mass) function
+// and a cumulative distribution function.
+type ProbCDFer interface {
+	Prob(x float64) float64
+	CDF(x float64) float64
+}
+
+// winnerAdaptiveQuadFunc is the function to integrate for the winner probs
+// of arbitrary distributions, in the same manner as
+// dirichletWinnerAdaptiveQuadFunc. The integration variable y in [0, 1] is
+// mapped to x by t, with derivative dx/dy.
+// Works in-place on result array.
+func winnerAdaptiveQuadFunc(y float64, dists []ProbCDFer, result []float64, t winnerTransform) {
+	x, jac := t.transform(y)
+	if math.IsInf(x, 0) || jac == 0 {
+		for j := range result {
+			result[j] = 0.0
+		}
+		return
+	}
+	cdfs := 1.0
+	var pdf, cdf float64
+	for j, d := range dists {
+		pdf = d.Prob(x)
+		cdf = d.CDF(x)
+		if cdf == 0.0 {
+			result[j] = 0.0
+			cdfs = 0.0
+			continue
+		}
+		result[j] = pdf / cdf
+		cdfs *= cdf
+	}
+	for j := range result {
+		result[j] *= cdfs * jac
+		if math.IsInf(result[j], 0) || math.IsNaN(result[j]) {
+			// Integrable singularity
+			result[j] = 0.0
+		}
+	}
+}
+
+// winnerTransform maps [0, 1] onto the support [min, max], with the bulk of
+// [0, 1] spread around center at the given scale.
+type winnerTransform struct {
+	min, max      float64
+	center, scale float64
+}
+
+// transform returns the point x corresponding to y, and the derivative dx/dy.
+func (t winnerTransform) transform(y float64) (x, jac float64) {
+	lo, hi := math.IsInf(t.min, -1), math.IsInf(t.max, 1)
+	switch {
+	case !lo && !hi:
+		return t.min + (t.max-t.min)*y, t.max - t.min
+	case !lo:
+		// x = min + scale*y/(1-y)
+		return t.min + t.scale*y/(1-y), t.scale / ((1 - y) * (1 - y))
+	case !hi:
+		// x = max - scale*(1-y)/y
+		return t.max - t.scale*(1-y)/y, t.scale / (y * y)
+	}
+	// x = center + scale*tan(π(y-1/2))
+	s, c := math.Sincos(math.Pi * (y - 0.5))
+	return t.center + t.scale*s/c, t.scale * math.Pi / (c * c)
+}
+
+// newWinnerTransform returns a winnerTransform for the distributions, centered
+// on the average of their medians and scaled by their spread.
+func newWinnerTransform(dists []ProbCDFer, min, max float64) winnerTransform {
+	t := winnerTransform{min: min, max: max}
+	if !math.IsInf(min, -1) && !math.IsInf(max, 1) {
+		return t
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, d := range dists {
+		q25 := cdfQuantile(d, 0.25, min, max)
+		q75 := cdfQuantile(d, 0.75, min, max)
+		lo = math.Min(lo, q25)
+		hi = math.Max(hi, q75)
+		t.center += (q25 + q75) / 2
+	}
+	t.center /= float64(len(dists))
+	t.scale = hi - lo
+	switch {
+	case !math.IsInf(min, -1):
+		t.scale = math.Max(t.scale, t.center-min)
+	case !math.IsInf(max, 1):
+		t.scale = math.Max(t.scale, max-t.center)
+	}
+	if !(t.scale > 0) || math.IsInf(t.scale, 1) {
+		t.scale = 1
+	}
+	return t
+}
+
+// cdfQuantile finds the p quantile of d within [min, max] by bisection on its CDF.
+func cdfQuantile(d ProbCDFer, p, min, max float64) float64 {
+	// Bracket the quantile, expanding outwards over infinite bounds.
+	lo, hi := min, max
+	if math.IsInf(lo, -1) {
+		lo = -1
+		if !math.IsInf(hi, 1) {
+			lo = hi - 1
+		}
+		for step := 1.0; d.CDF(lo) > p && !math.IsInf(lo, -1); step *= 2 {
+			lo -= step
+		}
+	}
+	if math.IsInf(hi, 1) {
+		hi = lo + 1
+		for step := 1.0; d.CDF(hi) < p && !math.IsInf(hi, 1); step *= 2 {
+			hi += step
+		}
+	}
+	for i := 0; i < 200; i++ {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			break
+		}
+		if d.CDF(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo + (hi-lo)/2
+}
+
+// WinnerProbabilities computes the probabilities that each of the independent
+// continuous random variables dists will be the largest, by integrating
+//  dists[j].Prob(x) * product(dists[i].CDF(x), i != j)
+// over the support [min, max] with the adaptive quadrature integration
+// technique of DirichletWinner. min and max may be infinite.
+// Infinite supports are mapped onto a finite interval centered on the
+// quartiles of the distributions.
+// WinnerProbabilities returns nil if tol is NaN or less than
+// MinDirichletWinnerTol.
+func WinnerProbabilities(dists []ProbCDFer, min, max, tol float64) []float64 {
+	if !(tol >= MinDirichletWinnerTol) {
+		return nil
+	}
+	n := len(dists)
+	if n <= 1 {
+		result := make([]float64, n)
+		if n == 1 {
+			result[0] = 1.0
+		}
+		return result
+	}
+	t := newWinnerTransform(dists, min, max)
+	q := newAdaptiveQuad(func(y float64, dst []float64) {
+		winnerAdaptiveQuadFunc(y, dists, dst, t)
+	}, n, tol)
+	q.integrate(n, 1, float64(2*n)*tol)
+	return q.result
+}
+
+// DiscreteWinnerProbabilities computes the probabilities that each of the
+// independent integer-valued random variables dists will be the largest,
+// with ties broken uniformly at random. That is, each of the k variables
+// sharing the maximum value wins with probability 1/k, as for Thompson sampling
+// with random tie-breaking. The result sums to 1 if the support of each
+// distribution is contained in [min, max].
+// Running time: O(n^2 * (max-min+1)) for n distributions.
+func DiscreteWinnerProbabilities(dists []ProbCDFer, min, max int) []float64 {
+	n := len(dists)
+	result := make([]float64, n)
+	pmfs := make([]float64, n)
+	cdfs := make([]float64, n) // P(X < k)
+	poly := make([]float64, n+1)
+	quot := make([]float64, n)
+	for k := min; k <= max; k++ {
+		x := float64(k)
+		// poly holds the coefficients of product((cdfs[j] + pmfs[j]*z), j),
+		// the generating function for the number of variables equal to k,
+		// given that none are greater than k.
+		poly[0] = 1.0
+		for j, d := range dists {
+			pmfs[j] = d.Prob(x)
+			cdfs[j] = d.CDF(x - 1)
+			polyMulLinear(poly[:j+2], cdfs[j], pmfs[j])
+		}
+		for i := range dists {
+			if pmfs[i] == 0 {
+				continue
+			}
+			polyDivLinear(quot, poly, cdfs[i], pmfs[i])
+			// Variable i ties with m others with probability quot[m],
+			// in which case it wins with probability 1/(m+1).
+			var p float64
+			for m, c := range quot {
+				p += c / float64(m+1)
+			}
+			result[i] += pmfs[i] * p
+		}
+	}
+	return result
+}
+
+// polyMulLinear multiplies the polynomial with coefficients poly[:len(poly)-1]
+// by (a + b*t), in-place on poly.
+func polyMulLinear(poly []float64, a, b float64) {
+	n := len(poly) - 1
+	poly[n] = 0.0
+	for m := n; m > 0; m-- {
+		poly[m] = poly[m]*a + poly[m-1]*b
+	}
+	poly[0] *= a
+}
+
+// polyDivLinear divides the polynomial with coefficients poly by (a + b*t),
+// where a, b >= 0, storing the len(poly)-1 coefficients of the quotient in dst.
+// The division runs from whichever end keeps it numerically stable, and
+// negative coefficients from rounding errors are clamped to 0.
+func polyDivLinear(dst, poly []float64, a, b float64) {
+	n := len(dst)
+	if a >= b {
+		dst[0] = poly[0] / a
+		for m := 1; m < n; m++ {
+			dst[m] = (poly[m] - b*dst[m-1]) / a
+		}
+	} else {
+		dst[n-1] = poly[n] / b
+		for m := n - 1; m > 0; m-- {
+			dst[m-1] = (poly[m] - a*dst[m]) / b
+		}
+	}
+	for m := range dst {
+		dst[m] = math.Max(dst[m], 0)
+	}
+}
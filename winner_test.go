@@ -0,0 +1,150 @@
+package statext
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestWinnerProbabilities(t *testing.T) {
+	// Gamma distributions with equal rates match DirichletWinner.
+	for _, a := range [][]float64{
+		{5.5, 10.5, 15.5},
+		{50.5, 100.5, 150.5},
+		{1, 2, 3, 4, 5},
+	} {
+		dists := make([]ProbCDFer, len(a))
+		for i, alpha := range a {
+			dists[i] = distuv.Gamma{Alpha: alpha, Beta: 3}
+		}
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			got := WinnerProbabilities(dists, 0, math.Inf(1), tol)
+			want := DirichletWinner(a, tol)
+			for i := range a {
+				if math.Abs(got[i]-want[i]) > 4*tol {
+					t.Errorf("Gamma winner mismatch. alphas: %v tol: %g got: %v want: %v", a, tol, got[i], want[i])
+				}
+			}
+		}
+	}
+
+	// Beta distributions on [0, 1] match BetaWinner.
+	alphas, betas := []float64{10, 12, 15}, []float64{90, 88, 85}
+	dists := make([]ProbCDFer, len(alphas))
+	for i := range alphas {
+		dists[i] = distuv.Beta{Alpha: alphas[i], Beta: betas[i]}
+	}
+	for _, tol := range []float64{1e-3, 1e-8} {
+		got := WinnerProbabilities(dists, 0, 1, tol)
+		want, _ := BetaWinner(alphas, betas, tol)
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 4*tol {
+				t.Errorf("Beta winner mismatch. tol: %g got: %v want: %v", tol, got[i], want[i])
+			}
+		}
+	}
+
+	// Two normals: P(X1 > X0) = Φ((μ1-μ0)/sqrt(σ0²+σ1²)), over both
+	// infinite and half-infinite supports.
+	for _, test := range []struct {
+		a, b     distuv.Normal
+		min, max float64
+	}{
+		{distuv.Normal{Mu: 0, Sigma: 1}, distuv.Normal{Mu: 0.5, Sigma: 2}, math.Inf(-1), math.Inf(1)},
+		{distuv.Normal{Mu: 1000, Sigma: 1}, distuv.Normal{Mu: 1001, Sigma: 0.1}, math.Inf(-1), math.Inf(1)},
+		{distuv.Normal{Mu: -1000, Sigma: 3}, distuv.Normal{Mu: -1002, Sigma: 1}, math.Inf(-1), 0},
+	} {
+		want := distuv.UnitNormal.CDF((test.b.Mu - test.a.Mu) / math.Hypot(test.a.Sigma, test.b.Sigma))
+		for _, tol := range []float64{1e-3, 1e-8, 1e-11} {
+			got := WinnerProbabilities([]ProbCDFer{test.a, test.b}, test.min, test.max, tol)
+			if math.Abs(got[1]-want) > 4*tol || math.Abs(got[0]+got[1]-1) > 4*tol {
+				t.Errorf("Normal winner mismatch. tol: %g got: %v want: %v", tol, got, []float64{1 - want, want})
+			}
+		}
+	}
+
+	for _, tol := range []float64{0, -1, math.NaN(), 1e-15} {
+		if got := WinnerProbabilities([]ProbCDFer{distuv.UnitNormal, distuv.UnitNormal}, math.Inf(-1), math.Inf(1), tol); got != nil {
+			t.Errorf("WinnerProbabilities accepted tol %v: got %v", tol, got)
+		}
+	}
+}
+
+func TestDiscreteWinnerProbabilities(t *testing.T) {
+	const tol = 1e-12
+	// Two fair coins: each wins outright with 1/4 and ties with 1/2.
+	coin := NewPoissonBinomial([]float64{0.5}, nil)
+	got := DiscreteWinnerProbabilities([]ProbCDFer{coin, coin}, 0, 1)
+	for i := range got {
+		if math.Abs(got[i]-0.5) > tol {
+			t.Errorf("Coin winner mismatch. Got %v, want 0.5", got[i])
+		}
+	}
+
+	for _, dists := range [][]ProbCDFer{
+		{
+			NewPoissonBinomial([]float64{0.1, 0.5, 0.9}, nil),
+			NewPoissonBinomial([]float64{0.6, 0.6}, nil),
+			BetaBinomial{N: 4, Alpha: 2, Beta: 3},
+		},
+		{
+			BetaBinomial{N: 5, Alpha: 1, Beta: 1},
+			BetaBinomial{N: 5, Alpha: 2, Beta: 1},
+			BetaBinomial{N: 3, Alpha: 5, Beta: 2},
+			NewPoissonBinomial([]float64{0.2, 0.4, 0.6, 0.8}, nil),
+		},
+	} {
+		got := DiscreteWinnerProbabilities(dists, -1, 6)
+		want := bruteForceDiscreteWinner(dists, 0, 5)
+		var sum float64
+		for i := range got {
+			sum += got[i]
+			if math.Abs(got[i]-want[i]) > tol {
+				t.Errorf("Discrete winner mismatch. Got %v, want %v", got[i], want[i])
+			}
+		}
+		if math.Abs(sum-1) > tol {
+			t.Errorf("Discrete winner large sum error: %g", 1-sum)
+		}
+	}
+}
+
+// bruteForceDiscreteWinner enumerates every outcome in [min, max]^n.
+func bruteForceDiscreteWinner(dists []ProbCDFer, min, max int) []float64 {
+	n := len(dists)
+	result := make([]float64, n)
+	x := make([]int, n)
+	for i := range x {
+		x[i] = min
+	}
+	for {
+		p := 1.0
+		best := x[0]
+		for i, d := range dists {
+			p *= d.Prob(float64(x[i]))
+			if x[i] > best {
+				best = x[i]
+			}
+		}
+		var ties float64
+		for _, v := range x {
+			if v == best {
+				ties++
+			}
+		}
+		for i, v := range x {
+			if v == best {
+				result[i] += p / ties
+			}
+		}
+		i := 0
+		for ; i < n && x[i] == max; i++ {
+			x[i] = min
+		}
+		if i == n {
+			return result
+		}
+		x[i]++
+	}
+}